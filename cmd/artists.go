@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/database"
+)
+
+var artistsCmd = &cobra.Command{
+	Use:   "artists [PVR...] [PROVIDER]",
+	Short: "Search for new artists",
+	Long: `This command can be used to search for new artists.
+
+When multiple PVRs are provided, the provider is queried once and the results are evaluated for each PVR.`,
+
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runArtists(args)
+	},
+}
+
+func runArtists(args []string) {
+	// init core
+	initCore()
+	showUsing()
+
+	// init database
+	if err := database.Init(flagDatabaseFile); err != nil {
+		log.WithError(err).Fatal("Failed opening database file")
+	}
+
+	// build job from cli
+	j := &config.Job{
+		Name:       "cli",
+		Pvr:        args[:len(args)-1],
+		Provider:   args[len(args)-1],
+		MediaType:  config.JobMediaTypeArtists,
+		SearchType: flagSearchType,
+		Limit:      flagLimit,
+		NoFilter:   flagNoFilter,
+		Params: map[string]string{
+			"country": flagCountry,
+			"genre":   flagGenre,
+		},
+	}
+
+	// run job
+	ctx, cancel := getSignalContext()
+	defer cancel()
+
+	ctx, cancelRuntime := getRuntimeContext(ctx)
+	defer cancelRuntime()
+
+	if err := runJob(ctx, j); err != nil {
+		log.WithError(err).Fatal("Failed searching for new artists")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(artistsCmd)
+
+	addArtistsFlags(artistsCmd)
+}
+
+func addArtistsFlags(cmd *cobra.Command) {
+	// required flags
+	cmd.Flags().StringVarP(&flagSearchType, "search-type", "t", "", "Search type.")
+	_ = cmd.MarkFlagRequired("search-type")
+
+	// optional flags
+	cmd.Flags().BoolVar(&flagNoFilter, "no-filter", false, "No filter expression checking.")
+	cmd.Flags().BoolVar(&flagExplain, "explain", false, "Explain which filter expression rejected each item.")
+	cmd.Flags().IntVar(&flagLimit, "limit", 0, "Max accepted items to add.")
+
+	cmd.Flags().StringVar(&flagCountry, "country", "", "Country to find popular artists in.")
+	cmd.Flags().StringVar(&flagGenre, "genre", "", "Genre to find popular artists in.")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l3uddz/mediarr/database"
+	providerObj "github.com/l3uddz/mediarr/provider"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth [PROVIDER]",
+	Short: "Authenticate with a provider",
+	Long:  `This command can be used to authenticate with a provider, e.g. trakt.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore()
+		showUsing()
+
+		// init database
+		if err := database.Init(flagDatabaseFile); err != nil {
+			log.WithError(err).Fatal("Failed opening database file")
+		}
+
+		// authenticate provider
+		ctx, cancel := getSignalContext()
+		defer cancel()
+
+		ctx, cancelRuntime := getRuntimeContext(ctx)
+		defer cancelRuntime()
+
+		providerName = args[0]
+
+		switch strings.ToLower(providerName) {
+		case "trakt":
+			if err := providerObj.NewTrakt().Authorize(ctx, getProviderConfig(providerName)); err != nil {
+				log.WithError(err).Fatalf("Failed authenticating with: %s", providerName)
+			}
+		default:
+			log.Fatalf("Authentication is not supported for: %s", providerName)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/database"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run configured jobs on a schedule",
+	Long: `This command can be used to keep mediarr running and run jobs on their configured schedule.
+
+Schedules use the cron format, e.g. "0 */6 * * *" or "@every 12h".`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore()
+		showUsing()
+
+		// init database
+		if err := database.Init(flagDatabaseFile); err != nil {
+			log.WithError(err).Fatal("Failed opening database file")
+		}
+
+		// cancel running jobs on shutdown signal
+		ctx, cancel := getSignalContext()
+		defer cancel()
+
+		// schedule jobs
+		var mtx sync.Mutex
+		// recover from a panicking job so the scheduler keeps running
+		c := cron.New(cron.WithChain(cron.Recover(cron.PrintfLogger(log))))
+		scheduledJobs := make(map[cron.EntryID]*config.Job)
+
+		for _, j := range config.Config.Jobs {
+			scheduledJob := j
+			if scheduledJob.Schedule == "" {
+				log.Debugf("Skipping job with no schedule: %s", scheduledJob.Name)
+				continue
+			}
+
+			id, err := c.AddFunc(scheduledJob.Schedule, func() {
+				// jobs share state, so only one can run at a time
+				mtx.Lock()
+				defer mtx.Unlock()
+
+				// skip when shutting down
+				if ctx.Err() != nil {
+					return
+				}
+
+				jobCtx, jobCancel := getRuntimeContext(ctx)
+				defer jobCancel()
+
+				log.Infof("Running job: %s", scheduledJob.Name)
+				if err := runJob(jobCtx, scheduledJob); err != nil {
+					log.WithError(err).Errorf("Failed running job: %s", scheduledJob.Name)
+				} else {
+					log.Infof("Finished job: %s", scheduledJob.Name)
+				}
+				log.Info("------------------")
+			})
+			if err != nil {
+				log.WithError(err).Fatalf("Failed scheduling job: %s", scheduledJob.Name)
+			}
+
+			scheduledJobs[id] = scheduledJob
+		}
+
+		if len(c.Entries()) == 0 {
+			log.Fatal("No jobs have been configured with a schedule")
+		}
+
+		// start scheduler
+		c.Start()
+
+		for _, entry := range c.Entries() {
+			log.WithFields(logrus.Fields{
+				"schedule": scheduledJobs[entry.ID].Schedule,
+				"next_run": entry.Next.Format(time.RFC3339),
+			}).Infof("Scheduled job: %s", scheduledJobs[entry.ID].Name)
+		}
+
+		log.WithField("jobs", len(c.Entries())).Info("Started scheduler")
+
+		// wait for shutdown signal
+		<-ctx.Done()
+		log.Info("Received shutdown signal, cancelling running jobs...")
+
+		<-c.Stop().Done()
+		log.Info("Stopped scheduler")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
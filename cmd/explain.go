@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [movies|shows|artists] [PVR...] [PROVIDER]",
+	Short: "Explain which filters reject media",
+	Long: `This command can be used to explain which filter expression rejects each item found by a provider.
+
+No media is added to the pvr.`,
+
+	Args: cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		flagExplain = true
+		flagDryRun = true
+
+		switch strings.ToLower(args[0]) {
+		case "movies":
+			runMovies(args[1:])
+		case "shows":
+			runShows(args[1:])
+		case "artists":
+			runArtists(args[1:])
+		default:
+			log.Fatalf("Unsupported media type %q, valid types: movies, shows, artists", args[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	addShowsFlags(explainCmd)
+}
+
+/* Private Helpers */
+
+func (t *pvrTarget) explainMediaItem(mediaItem *config.MediaItem) bool {
+	match, err := t.pvr.ExplainIgnore(mediaItem)
+	if err != nil {
+		t.log.WithError(err).Errorf("Failed evaluating filter expressions against: %+v", mediaItem)
+		return false
+	} else if match == nil {
+		return true
+	}
+
+	t.log.WithFields(logrus.Fields{
+		"filter":     match.Type,
+		"index":      match.Index,
+		"expression": match.Expression,
+	}).Infof("Rejected: %s", mediaItem.String())
+
+	t.explainRejections[match.String()]++
+	t.queueExcludeMediaItem(mediaItem, match)
+	return false
+}
+
+func (t *pvrTarget) showExplainSummary() {
+	if len(t.explainRejections) == 0 {
+		t.log.Info("No items were rejected by filter expressions")
+		return
+	}
+
+	// sort expressions by rejections
+	expressions := make([]string, 0, len(t.explainRejections))
+	for expression := range t.explainRejections {
+		expressions = append(expressions, expression)
+	}
+
+	sort.Slice(expressions, func(i, j int) bool {
+		if t.explainRejections[expressions[i]] == t.explainRejections[expressions[j]] {
+			return expressions[i] < expressions[j]
+		}
+		return t.explainRejections[expressions[i]] > t.explainRejections[expressions[j]]
+	})
+
+	// show rejections
+	log.Info("------------------")
+	for _, expression := range expressions {
+		t.log.Infof("Rejected %04d: %s", t.explainRejections[expression], expression)
+	}
+}
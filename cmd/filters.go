@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+	pvrObj "github.com/l3uddz/mediarr/pvr"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type filterFixture struct {
+	Name             string
+	Expect           string
+	config.MediaItem `mapstructure:",squash"`
+}
+
+var (
+	flagFixturesFile string
+)
+
+var filtersCmd = &cobra.Command{
+	Use:   "filters",
+	Short: "Filter expression tools",
+	Long:  `This command can be used to work with the filter expressions of a pvr.`,
+}
+
+var filtersTestCmd = &cobra.Command{
+	Use:   "test [PVR]",
+	Short: "Test filter expressions against fixture items",
+	Long: `This command can be used to test the filter expressions of a pvr against media items from a fixtures file.
+
+The fixtures file can be yaml or json and must contain a list of items, e.g.
+
+items:
+  - name: wrestling
+    expect: reject
+    provider: trakt
+    title: WWE Raw
+    year: 2020
+    date: 2020-01-06
+    genres: [sports]
+    languages: [en]
+
+No requests are sent to any provider or pvr.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore()
+
+		// validate pvr exists in config
+		pvrName := args[0]
+
+		pvrCfg, ok := config.Config.Pvr[pvrName]
+		if !ok {
+			log.Fatalf("No pvr configuration found for: %q", pvrName)
+		}
+
+		// compile filter expressions
+		filters, err := pvrObj.NewFilters(&pvrCfg.Filters)
+		if err != nil {
+			log.WithError(err).Fatal("Failed compiling filter expressions")
+		}
+
+		// load fixtures
+		fixtures, err := loadFilterFixtures(flagFixturesFile)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading fixtures")
+		}
+
+		// test fixtures
+		failed := 0
+
+		for pos, f := range fixtures {
+			fixture := f
+			name := fixture.Name
+			if name == "" {
+				name = fixture.MediaItem.String()
+			}
+
+			// evaluate filters
+			match, err := filters.ExplainIgnore(&fixture.MediaItem)
+			if err != nil {
+				log.WithError(err).Errorf("Failed %02d/%02d: %s", pos+1, len(fixtures), name)
+				failed++
+				continue
+			}
+
+			result, status, reason := "accept", "Accepted", ""
+			if match != nil {
+				result, status, reason = "reject", "Rejected", " ("+match.String()+")"
+			}
+
+			// validate expected outcome
+			switch {
+			case fixture.Expect == "":
+				log.Infof("%s %02d/%02d: %s%s", status, pos+1, len(fixtures), name, reason)
+			case strings.EqualFold(fixture.Expect, result):
+				log.Infof("Passed %02d/%02d: %s => %s%s", pos+1, len(fixtures), name, result, reason)
+			default:
+				log.Errorf("Failed %02d/%02d: %s => expected %s, got %s%s", pos+1, len(fixtures), name,
+					fixture.Expect, result, reason)
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			log.Fatalf("%d of %d fixtures failed", failed, len(fixtures))
+		}
+
+		log.Infof("All %d fixtures passed", len(fixtures))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filtersCmd)
+	filtersCmd.AddCommand(filtersTestCmd)
+
+	// required flags
+	filtersTestCmd.Flags().StringVarP(&flagFixturesFile, "fixtures", "f", "", "Fixtures file (yaml or json).")
+	_ = filtersTestCmd.MarkFlagRequired("fixtures")
+}
+
+/* Private Helpers */
+
+func loadFilterFixtures(fixturesFilePath string) ([]filterFixture, error) {
+	v := viper.New()
+	v.SetConfigFile(fixturesFilePath)
+
+	// read fixtures
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "failed reading fixtures")
+	}
+
+	// decode fixtures
+	var fixtures []filterFixture
+	if err := v.UnmarshalKey("items", &fixtures, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeHookFunc("2006-01-02"),
+		mapstructure.StringToSliceHookFunc(","),
+	))); err != nil {
+		return nil, errors.Wrap(err, "failed decoding fixtures")
+	}
+
+	// validate fixtures
+	for pos, fixture := range fixtures {
+		switch strings.ToLower(fixture.Expect) {
+		case "", "accept", "reject":
+			break
+		default:
+			return nil, fmt.Errorf("invalid expect value for fixture %d: %q (valid values: accept, reject)",
+				pos+1, fixture.Expect)
+		}
+
+		// default year from date
+		if fixture.Year == 0 && !fixture.Date.IsZero() {
+			fixtures[pos].Year = fixture.Date.Year()
+		}
+	}
+
+	if len(fixtures) == 0 {
+		return nil, errors.New("no fixture items found")
+	}
+
+	return fixtures, nil
+}
@@ -1,16 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/l3uddz/mediarr/config"
 	"github.com/l3uddz/mediarr/database"
 	"github.com/l3uddz/mediarr/logger"
 	providerObj "github.com/l3uddz/mediarr/provider"
-	pvrObj "github.com/l3uddz/mediarr/pvr"
 	"github.com/l3uddz/mediarr/release"
 	"github.com/l3uddz/mediarr/utils/paths"
 	stringutils "github.com/l3uddz/mediarr/utils/strings"
@@ -27,9 +30,11 @@ var (
 	flagConfigFile   = "config.yaml"
 	flagDatabaseFile = "vault.db"
 	flagLogFile      = "activity.log"
+	flagMaxRuntime   time.Duration
 
 	flagSearchType string
 	flagNoFilter   bool
+	flagExplain    bool
 	flagLimit      int
 
 	flaglistUser string
@@ -40,6 +45,9 @@ var (
 	flagGenre    string
 	flagYear     string
 	flagRating   string
+	flagVotes    string
+	flagReleased string
+	flagSort     string
 	flagNetwork  string
 	flagStatus   string
 	flagDryRun   bool
@@ -47,11 +55,9 @@ var (
 	// Global vars
 	log *logrus.Entry
 
-	pvrName   string
-	pvrConfig *config.Pvr
-	pvr       pvrObj.Interface
+	job *config.Job
 
-	existingMediaItems map[string]config.MediaItem
+	pvrs []*pvrTarget
 
 	providerName      string
 	lowerProviderName string
@@ -83,6 +89,7 @@ func init() {
 	rootCmd.PersistentFlags().CountVarP(&flagLogLevel, "verbose", "v", "Verbose level")
 
 	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Dry run mode")
+	rootCmd.PersistentFlags().DurationVar(&flagMaxRuntime, "max-runtime", 0, "Max runtime of a run, e.g. 30m")
 }
 
 func initCore() {
@@ -134,31 +141,51 @@ func showUsing() {
 
 /* Private Helpers */
 
-func parseValidateInputs(args []string) error {
-	var ok bool
+func getSignalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	// restore default behaviour once cancelled, so a second signal exits immediately
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return ctx, stop
+}
+
+func getRuntimeContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if flagMaxRuntime > 0 {
+		return context.WithTimeout(parent, flagMaxRuntime)
+	}
+
+	return context.WithCancel(parent)
+}
+
+func parseValidateInputs() error {
 	var err error
 
-	// validate cli flags
-	if flagSearchType == "person" && flagQueryStr == "" {
+	// validate job inputs
+	if job.SearchType == "person" && job.Params["query"] == "" {
 		return errors.New("person search must have a --query string, e.g. bryan-cranston")
 	}
 
-	// validate pvr exists in config
-	pvrName = args[0]
-
-	pvrConfig, ok = config.Config.Pvr[pvrName]
-	if !ok {
-		return fmt.Errorf("no pvr configuration found for: %q", pvrName)
+	// set pvrs
+	if len(job.Pvr) == 0 {
+		return errors.New("at least one pvr must be provided")
 	}
 
-	// set pvr
-	pvr, err = pvrObj.Get(pvrName, pvrConfig.Type, pvrConfig)
-	if err != nil {
-		return errors.WithMessage(err, "failed loading pvr object")
+	pvrs = make([]*pvrTarget, 0, len(job.Pvr))
+	for _, name := range job.Pvr {
+		t, err := newPvrTarget(name)
+		if err != nil {
+			return err
+		}
+
+		pvrs = append(pvrs, t)
 	}
 
 	// set provider
-	providerName = args[1]
+	providerName = job.Provider
 	lowerProviderName = strings.ToLower(providerName)
 
 	provider, err = providerObj.Get(lowerProviderName)
@@ -167,10 +194,15 @@ func parseValidateInputs(args []string) error {
 	}
 
 	// set provider config if exists
+	providerCfg = getProviderConfig(providerName)
+
+	return nil
+}
+
+func getProviderConfig(name string) map[string]string {
 	for pName, pCfg := range config.Config.Provider {
-		if strings.EqualFold(pName, providerName) {
-			providerCfg = pCfg
-			break
+		if strings.EqualFold(pName, name) {
+			return pCfg
 		}
 	}
 
@@ -178,38 +210,68 @@ func parseValidateInputs(args []string) error {
 }
 
 func shouldAcceptMediaItem(mediaItem *config.MediaItem) bool {
-	if flagNoFilter {
-		// when no-filter is enabled, dont check ignore filters
-		return true
+	accepted := false
+
+	// every pvr evaluates the item with its own filters
+	for _, t := range pvrs {
+		t.candidate = t.shouldAcceptMediaItem(mediaItem)
+		accepted = accepted || t.candidate
 	}
 
-	if ignore, err := pvr.ShouldIgnore(mediaItem); err != nil {
-		log.WithError(err).Errorf("Failed evaluating ignore expressions against: %+v", mediaItem)
-		return false
-	} else if ignore {
-		return false
+	return accepted
+}
+
+func validateMediaItem(ctx context.Context, mediaItem *config.MediaItem) bool {
+	validated := false
+
+	// the item is received by every pvr that accepted it
+	for _, t := range pvrs {
+		if !t.candidate {
+			continue
+		}
+
+		t.candidate = false
+		if t.validateMediaItem(ctx, mediaItem) {
+			t.mediaItems = append(t.mediaItems, *mediaItem)
+			validated = true
+		}
 	}
 
-	return true
+	return validated
 }
 
-func ignoreExistingMediaItem(mediaItem *config.MediaItem) bool {
+func isEveryPvrFull() bool {
+	if job.Limit < 1 {
+		return false
+	}
 
-	if mediaItem.TvdbId != "" {
-		if _, exists := existingMediaItems[mediaItem.TvdbId]; exists {
-			return true
+	for _, t := range pvrs {
+		if len(t.mediaItems) < job.Limit {
+			return false
 		}
 	}
-	if mediaItem.TmdbId != "" {
-		if _, exists := existingMediaItems[mediaItem.TmdbId]; exists {
-			return true
+
+	return true
+}
+
+func ignoreExcludedMediaItem(mediaItem *config.MediaItem) bool {
+	// only ignore items excluded by every pvr
+	for _, t := range pvrs {
+		if !t.existingMediaItems.IsExcluded(mediaItem) {
+			return false
 		}
 	}
-	if mediaItem.ImdbId != "" {
-		if _, exists := existingMediaItems[mediaItem.ImdbId]; exists {
-			return true
+
+	return true
+}
+
+func ignoreExistingMediaItem(mediaItem *config.MediaItem) bool {
+	// only ignore items every pvr already has
+	for _, t := range pvrs {
+		if !t.existingMediaItems.Contains(mediaItem) {
+			return false
 		}
 	}
 
-	return false
+	return true
 }
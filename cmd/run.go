@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/database"
+	providerObj "github.com/l3uddz/mediarr/provider"
+	pvrObj "github.com/l3uddz/mediarr/pvr"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [JOB...]",
+	Short: "Run configured jobs",
+	Long: `This command can be used to run jobs defined in the configuration file.
+
+When no job names are provided, all jobs are run in the order they are configured.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore()
+		showUsing()
+
+		// determine jobs to run
+		jobs, err := getJobs(args)
+		if err != nil {
+			log.WithError(err).Fatal("Failed validating inputs")
+		}
+
+		// init database
+		if err := database.Init(flagDatabaseFile); err != nil {
+			log.WithError(err).Fatal("Failed opening database file")
+		}
+
+		// run jobs
+		ctx, cancel := getSignalContext()
+		defer cancel()
+
+		ctx, cancelRuntime := getRuntimeContext(ctx)
+		defer cancelRuntime()
+
+		failed := 0
+
+		for pos, j := range jobs {
+			log.Infof("Running job %02d/%02d: %s", pos+1, len(jobs), j.Name)
+
+			if err := runJob(ctx, j); err != nil {
+				log.WithError(err).Errorf("Failed running job: %s", j.Name)
+				failed++
+			}
+
+			log.Info("------------------")
+
+			// stop when cancelled or max runtime reached
+			if ctx.Err() != nil {
+				log.WithError(ctx.Err()).Errorf("Skipping %d remaining jobs", len(jobs)-pos-1)
+				failed += len(jobs) - pos - 1
+				break
+			}
+		}
+
+		if failed > 0 {
+			log.Fatalf("%d of %d jobs failed", failed, len(jobs))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().BoolVar(&flagExplain, "explain", false, "Explain which filter expression rejected each item.")
+}
+
+/* Private Helpers */
+
+func getJobs(names []string) ([]*config.Job, error) {
+	if len(config.Config.Jobs) == 0 {
+		return nil, errors.New("no jobs have been configured")
+	}
+
+	// run all jobs
+	if len(names) == 0 {
+		return config.Config.Jobs, nil
+	}
+
+	// find named jobs
+	jobs := make([]*config.Job, 0)
+
+	for _, name := range names {
+		var found *config.Job
+		for _, j := range config.Config.Jobs {
+			if strings.EqualFold(j.Name, name) {
+				found = j
+				break
+			}
+		}
+
+		if found == nil {
+			return nil, fmt.Errorf("no job configuration found for: %q", name)
+		}
+
+		jobs = append(jobs, found)
+	}
+
+	return jobs, nil
+}
+
+func runJob(ctx context.Context, j *config.Job) error {
+	var err error
+
+	// set job
+	job = j
+	if job.Params == nil {
+		job.Params = make(map[string]string)
+	}
+
+	// determine media type
+	var providerMediaType providerObj.MediaType
+	var pvrMediaType pvrObj.MediaType
+
+	switch strings.ToLower(job.MediaType) {
+	case config.JobMediaTypeMovies, "movie":
+		providerMediaType, pvrMediaType = providerObj.Movie, pvrObj.MOVIE
+	case config.JobMediaTypeShows, "show":
+		providerMediaType, pvrMediaType = providerObj.Show, pvrObj.SHOW
+	case config.JobMediaTypeArtists, "artist":
+		providerMediaType, pvrMediaType = providerObj.Music, pvrObj.MUSIC
+	default:
+		return fmt.Errorf("unsupported media type %q, valid types: movies, shows, artists", job.MediaType)
+	}
+
+	// validate core inputs
+	if err := parseValidateInputs(); err != nil {
+		return errors.WithMessage(err, "failed validating inputs")
+	}
+
+	// init provider object
+	if err := provider.Init(ctx, providerMediaType, providerCfg); err != nil {
+		return errors.WithMessagef(err, "failed initializing provider object for: %s", providerName)
+	}
+
+	provider.SetIgnoreExistingMediaItemFn(ignoreExistingMediaItem)
+	provider.SetIgnoreExcludedMediaItemFn(ignoreExcludedMediaItem)
+	provider.SetAcceptMediaItemFn(shouldAcceptMediaItem)
+	provider.SetValidateMediaItemFn(validateMediaItem)
+
+	// validate provider supports search type
+	supported, searchTypes := provider.SupportsMoviesSearchType(job.SearchType), provider.GetMoviesSearchTypes()
+	switch providerMediaType {
+	case providerObj.Show:
+		supported, searchTypes = provider.SupportsShowsSearchType(job.SearchType), provider.GetShowsSearchTypes()
+	case providerObj.Music:
+		supported, searchTypes = provider.SupportsArtistsSearchType(job.SearchType), provider.GetArtistsSearchTypes()
+	}
+
+	if !supported {
+		return fmt.Errorf("unsupported search type %q, valid types: %s", job.SearchType,
+			strings.Join(searchTypes, ", "))
+	}
+
+	// init pvr objects
+	for _, t := range pvrs {
+		if err := t.init(ctx, pvrMediaType); err != nil {
+			return err
+		}
+	}
+
+	// build logic map
+	logic := map[string]interface{}{
+		"limit": job.Limit,
+	}
+
+	if len(pvrs) > 1 {
+		// every pvr has its own limit, so stop once they are all full
+		logic["limit"] = 0
+		logic["limit_reached"] = isEveryPvrFull
+	}
+
+	// retrieve media
+	switch providerMediaType {
+	case providerObj.Show:
+		_, err = provider.GetShows(ctx, job.SearchType, logic, job.Params)
+	case providerObj.Music:
+		_, err = provider.GetArtists(ctx, job.SearchType, logic, job.Params)
+	default:
+		_, err = provider.GetMovies(ctx, job.SearchType, logic, job.Params)
+	}
+
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving media from provider")
+	}
+
+	// add the items each pvr received
+	for _, t := range pvrs {
+		added, err := t.addMedia(ctx)
+		if err != nil {
+			return err
+		}
+
+		// exclude items rejected by exclude tagged ignore expressions
+		excluded, err := t.excludeMedia(ctx)
+		if err != nil {
+			return err
+		}
+
+		t.log.WithFields(logrus.Fields{
+			"received": len(t.mediaItems),
+			"added":    added,
+			"excluded": excluded,
+		}).Info("Finished adding media items")
+
+		// show filter rejections
+		if flagExplain {
+			t.showExplainSummary()
+		}
+	}
+
+	return nil
+}
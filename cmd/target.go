@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+	pvrObj "github.com/l3uddz/mediarr/pvr"
+	"github.com/l3uddz/mediarr/utils/media"
+	stringutils "github.com/l3uddz/mediarr/utils/strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+/* Struct */
+
+// pvrTarget holds the state of a single pvr during a job run
+type pvrTarget struct {
+	name string
+	cfg  *config.Pvr
+	pvr  pvrObj.Interface
+	log  *logrus.Entry
+
+	existingMediaItems *config.MediaIndex
+	excludeMediaIndex  *config.MediaIndex
+	excludeMediaItems  []config.MediaItem
+	explainRejections  map[string]int
+	mediaItems         []config.MediaItem
+
+	// set when the item currently being evaluated was accepted
+	candidate bool
+}
+
+/* Initializer */
+
+func newPvrTarget(name string) (*pvrTarget, error) {
+	// validate pvr exists in config
+	cfg, ok := config.Config.Pvr[name]
+	if !ok {
+		return nil, fmt.Errorf("no pvr configuration found for: %q", name)
+	}
+
+	// set pvr
+	p, err := pvrObj.Get(name, cfg.Type, cfg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed loading pvr object")
+	}
+
+	return &pvrTarget{
+		name: name,
+		cfg:  cfg,
+		pvr:  p,
+		log:  log.WithField("pvr", name),
+	}, nil
+}
+
+/* Private */
+
+func (t *pvrTarget) init(ctx context.Context, mediaType pvrObj.MediaType) error {
+	var err error
+
+	// init pvr object
+	if err := t.pvr.Init(ctx, mediaType); err != nil {
+		return errors.WithMessagef(err, "failed initializing pvr object for: %s", t.name)
+	}
+
+	log.Infof("Using %s = %s (%s %s)", stringutils.StringLeftJust("PVR", " ", 10), t.name,
+		strings.ToLower(t.cfg.Type), t.pvr.GetVersion())
+
+	// get existing media
+	t.existingMediaItems, err = t.pvr.GetExistingMedia(ctx)
+	if err != nil {
+		return errors.WithMessagef(err, "failed retrieving existing media from pvr: %s", t.name)
+	}
+
+	// merge existing media of sibling pvrs
+	for _, name := range t.cfg.AlsoCheck {
+		if err := t.mergeExistingMedia(ctx, mediaType, name); err != nil {
+			return errors.WithMessagef(err, "failed checking existing media of pvr: %s", name)
+		}
+	}
+
+	// reset job state
+	t.excludeMediaIndex, t.excludeMediaItems = config.NewMediaIndex(), make([]config.MediaItem, 0)
+	t.explainRejections = make(map[string]int)
+	t.mediaItems = make([]config.MediaItem, 0)
+
+	return nil
+}
+
+func (t *pvrTarget) mergeExistingMedia(ctx context.Context, mediaType pvrObj.MediaType, name string) error {
+	if strings.EqualFold(name, t.name) {
+		return nil
+	}
+
+	// validate pvr exists in config
+	cfg, ok := config.Config.Pvr[name]
+	if !ok {
+		return fmt.Errorf("no pvr configuration found for: %q", name)
+	}
+
+	// init sibling pvr
+	p, err := pvrObj.Get(name, cfg.Type, cfg)
+	if err != nil {
+		return errors.WithMessage(err, "failed loading pvr object")
+	}
+
+	if err := p.Init(ctx, mediaType); err != nil {
+		return errors.WithMessage(err, "failed initializing pvr object")
+	}
+
+	// get existing media
+	existingMediaItems, err := p.GetExistingMedia(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving existing media")
+	}
+
+	t.existingMediaItems.Merge(existingMediaItems)
+
+	t.log.WithFields(logrus.Fields{
+		"also_check": name,
+		"existing":   t.existingMediaItems.Size(),
+		"excluded":   t.existingMediaItems.ExcludedSize(),
+	}).Info("Merged existing media")
+	return nil
+}
+
+func (t *pvrTarget) shouldAcceptMediaItem(mediaItem *config.MediaItem) bool {
+	// does the pvr already have this item?
+	if t.existingMediaItems.Contains(mediaItem) {
+		return false
+	}
+
+	// stop when limit reached
+	if job.Limit > 0 && len(t.mediaItems) >= job.Limit {
+		return false
+	}
+
+	if job.NoFilter {
+		// when no-filter is enabled, dont check ignore filters
+		return true
+	}
+
+	if flagExplain {
+		// when explain is enabled, record which expression rejected the item
+		return t.explainMediaItem(mediaItem)
+	}
+
+	match, err := t.pvr.ExplainIgnore(mediaItem)
+	if err != nil {
+		t.log.WithError(err).Errorf("Failed evaluating ignore expressions against: %+v", mediaItem)
+		return false
+	} else if match != nil {
+		t.queueExcludeMediaItem(mediaItem, match)
+		return false
+	}
+
+	return true
+}
+
+func (t *pvrTarget) validateMediaItem(ctx context.Context, mediaItem *config.MediaItem) bool {
+	// the pvr lookup determines whether the item exists upstream
+	exists, err := t.pvr.LookupMedia(ctx, mediaItem)
+	if err != nil {
+		t.log.WithError(err).Errorf("Failed looking up media item: %s", mediaItem.String())
+		return false
+	}
+
+	return exists
+}
+
+func (t *pvrTarget) queueExcludeMediaItem(mediaItem *config.MediaItem, match *pvrObj.FilterMatch) {
+	if !match.Exclude {
+		return
+	}
+
+	// only queue each item once
+	if t.excludeMediaIndex.Add(*mediaItem) {
+		t.excludeMediaItems = append(t.excludeMediaItems, *mediaItem)
+	}
+}
+
+func (t *pvrTarget) addMedia(ctx context.Context) (int, error) {
+	sortedMediaItems := media.SortMediaItems(t.mediaItems, media.SortTypeReleaseDate)
+	itemsSize := len(sortedMediaItems)
+	added := 0
+
+	for pos, m := range sortedMediaItems {
+		mediaItem := m
+
+		// stop when cancelled or max runtime reached
+		if err := ctx.Err(); err != nil {
+			return added, errors.WithMessagef(err, "stopped after adding %d of %d items", pos, itemsSize)
+		}
+
+		// skip when dry-run is enabled
+		if flagDryRun {
+			t.log.Infof("Adding %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+			continue
+		}
+
+		// add media
+		t.log.Debugf("Adding %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+		if err := t.pvr.AddMedia(ctx, &mediaItem); err != nil {
+			t.log.WithError(err).Errorf("Failed %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+		} else {
+			t.log.Infof("Added %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+			added++
+		}
+	}
+
+	return added, nil
+}
+
+func (t *pvrTarget) excludeMedia(ctx context.Context) (int, error) {
+	itemsSize := len(t.excludeMediaItems)
+	excluded := 0
+
+	for pos, m := range t.excludeMediaItems {
+		mediaItem := m
+
+		// stop when cancelled or max runtime reached
+		if err := ctx.Err(); err != nil {
+			return excluded, errors.WithMessagef(err, "stopped after excluding %d of %d items", pos, itemsSize)
+		}
+
+		// skip when dry-run is enabled
+		if flagDryRun {
+			t.log.Infof("Excluding %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+			continue
+		}
+
+		// exclude media
+		t.log.Debugf("Excluding %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+		if err := t.pvr.ExcludeMedia(ctx, &mediaItem); err != nil {
+			t.log.WithError(err).Errorf("Failed excluding %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+		} else {
+			t.log.Infof("Excluded %02d/%02d: %s", pos+1, itemsSize, mediaItem.String())
+			excluded++
+		}
+	}
+
+	return excluded, nil
+}
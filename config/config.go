@@ -8,6 +8,7 @@ import (
 	stringutils "github.com/l3uddz/mediarr/utils/strings"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
@@ -15,6 +16,7 @@ import (
 type Configuration struct {
 	Pvr      map[string]*Pvr
 	Provider map[string]map[string]string
+	Jobs     []*Job
 }
 
 /* Vars */
@@ -77,7 +79,7 @@ func Init(configFilePath string) error {
 	}
 
 	// Unmarshal into Config struct
-	if err := viper.Unmarshal(&Config); err != nil {
+	if err := viper.Unmarshal(&Config, viper.DecodeHook(getDecodeHook())); err != nil {
 		log.WithError(err).Error("Configuration decode error")
 		return errors.Wrap(err, "failed decoding config")
 	}
@@ -130,3 +132,12 @@ func setConfigDefaults(check bool) error {
 
 	return nil
 }
+
+func getDecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		stringToStringListHookFunc(),
+		stringToPvrTagHookFunc(),
+		stringToPvrIgnoreHookFunc(),
+	)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+/* Test Decode Hook */
+
+func TestDecodeHook(t *testing.T) {
+	input := map[string]interface{}{
+		"jobs": []interface{}{
+			map[string]interface{}{"name": "job", "pvr": "radarr, radarr4k"},
+		},
+		"pvr": map[string]interface{}{
+			"radarr": map[string]interface{}{
+				"also_check": "radarr4k",
+				"tags":       []interface{}{"mediarr"},
+				"filters": map[string]interface{}{
+					"accepts": `Genres contains "a,b"`,
+					"ignores": []interface{}{`Title in ["A", "B"]`},
+				},
+			},
+		},
+	}
+
+	var cfg Configuration
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       getDecodeHook(),
+		WeaklyTypedInput: true,
+		Result:           &cfg,
+	})
+	if err != nil {
+		t.Fatalf("Failed creating decoder: %v", err)
+	}
+
+	if err := decoder.Decode(input); err != nil {
+		t.Fatalf("Failed decoding config: %v", err)
+	}
+
+	// name lists are split on commas
+	if want := (StringList{"radarr", "radarr4k"}); !reflect.DeepEqual(cfg.Jobs[0].Pvr, want) {
+		t.Errorf("Expected job pvr %v, got %v", want, cfg.Jobs[0].Pvr)
+	}
+
+	radarr := cfg.Pvr["radarr"]
+	if want := (StringList{"radarr4k"}); !reflect.DeepEqual(radarr.AlsoCheck, want) {
+		t.Errorf("Expected also_check %v, got %v", want, radarr.AlsoCheck)
+	}
+
+	// expressions are never split
+	if want := []string{`Genres contains "a,b"`}; !reflect.DeepEqual(radarr.Filters.Accepts, want) {
+		t.Errorf("Expected accepts %q, got %q", want, radarr.Filters.Accepts)
+	}
+
+	if want := []PvrIgnore{{When: `Title in ["A", "B"]`}}; !reflect.DeepEqual(radarr.Filters.Ignores, want) {
+		t.Errorf("Expected ignores %v, got %v", want, radarr.Filters.Ignores)
+	}
+
+	if want := []PvrTag{{Name: "mediarr"}}; !reflect.DeepEqual(radarr.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, radarr.Tags)
+	}
+}
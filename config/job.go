@@ -0,0 +1,19 @@
+package config
+
+type Job struct {
+	Name       string
+	Pvr        StringList
+	Provider   string
+	MediaType  string `mapstructure:"media_type"`
+	SearchType string `mapstructure:"search_type"`
+	Limit      int
+	NoFilter   bool `mapstructure:"no_filter"`
+	Params     map[string]string
+	Schedule   string
+}
+
+const (
+	JobMediaTypeMovies  string = "movies"
+	JobMediaTypeShows   string = "shows"
+	JobMediaTypeArtists string = "artists"
+)
@@ -0,0 +1,147 @@
+package config
+
+import "strings"
+
+type MediaIdSource string
+
+const (
+	MediaIdSourceTvdb MediaIdSource = "tvdb"
+	MediaIdSourceTmdb MediaIdSource = "tmdb"
+	MediaIdSourceImdb MediaIdSource = "imdb"
+	MediaIdSourceMbid MediaIdSource = "mbid"
+)
+
+type MediaId struct {
+	Source MediaIdSource
+	Id     string
+}
+
+type MediaIndex struct {
+	items        map[MediaId]MediaItem
+	excluded     map[MediaId]MediaItem
+	size         int
+	excludedSize int
+}
+
+/* Public */
+
+func NewMediaIndex() *MediaIndex {
+	return &MediaIndex{
+		items:    make(map[MediaId]MediaItem),
+		excluded: make(map[MediaId]MediaItem),
+	}
+}
+
+func (m *MediaItem) GetIds() []MediaId {
+	ids := make([]MediaId, 0, 4)
+
+	if m.TvdbId != "" {
+		ids = append(ids, MediaId{Source: MediaIdSourceTvdb, Id: m.TvdbId})
+	}
+	if m.TmdbId != "" {
+		ids = append(ids, MediaId{Source: MediaIdSourceTmdb, Id: m.TmdbId})
+	}
+	if m.ImdbId != "" {
+		ids = append(ids, MediaId{Source: MediaIdSourceImdb, Id: strings.ToLower(m.ImdbId)})
+	}
+	if m.MbId != "" {
+		ids = append(ids, MediaId{Source: MediaIdSourceMbid, Id: strings.ToLower(m.MbId)})
+	}
+
+	return ids
+}
+
+func (i *MediaIndex) Add(item MediaItem) bool {
+	if !addToIndex(i.items, item) {
+		return false
+	}
+
+	i.size++
+	return true
+}
+
+func (i *MediaIndex) AddExcluded(item MediaItem) bool {
+	if !addToIndex(i.excluded, item) {
+		return false
+	}
+
+	i.excludedSize++
+	return true
+}
+
+// Merge adds the items, and excluded items, of another index
+func (i *MediaIndex) Merge(other *MediaIndex) {
+	for _, item := range other.items {
+		i.Add(item)
+	}
+
+	for _, item := range other.excluded {
+		i.AddExcluded(item)
+	}
+}
+
+func (i *MediaIndex) Get(source MediaIdSource, id string) (*MediaItem, bool) {
+	if id == "" {
+		return nil, false
+	}
+
+	if source == MediaIdSourceImdb || source == MediaIdSourceMbid {
+		id = strings.ToLower(id)
+	}
+
+	item, exists := i.items[MediaId{Source: source, Id: id}]
+	if !exists {
+		return nil, false
+	}
+
+	return &item, true
+}
+
+func (i *MediaIndex) ContainsId(source MediaIdSource, id string) bool {
+	_, exists := i.Get(source, id)
+	return exists
+}
+
+// Contains reports whether the item exists, or has been excluded
+func (i *MediaIndex) Contains(item *MediaItem) bool {
+	return indexContains(i.items, item) || indexContains(i.excluded, item)
+}
+
+func (i *MediaIndex) IsExcluded(item *MediaItem) bool {
+	return indexContains(i.excluded, item)
+}
+
+func (i *MediaIndex) Size() int {
+	return i.size
+}
+
+func (i *MediaIndex) ExcludedSize() int {
+	return i.excludedSize
+}
+
+/* Private */
+
+func addToIndex(items map[MediaId]MediaItem, item MediaItem) bool {
+	added := false
+
+	for _, id := range item.GetIds() {
+		if _, exists := items[id]; exists {
+			continue
+		}
+
+		items[id] = item
+		added = true
+	}
+
+	return added
+}
+
+func indexContains(items map[MediaId]MediaItem, item *MediaItem) bool {
+	for _, id := range item.GetIds() {
+		if _, exists := items[id]; exists {
+			return true
+		}
+	}
+
+	return false
+}
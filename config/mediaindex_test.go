@@ -0,0 +1,52 @@
+package config
+
+import "testing"
+
+/* Test Media Index */
+
+func TestMediaIndex(t *testing.T) {
+	index := NewMediaIndex()
+	index.Add(MediaItem{Title: "Movie", TmdbId: "12345", ImdbId: "tt0012345"})
+	index.Add(MediaItem{Title: "Show", TvdbId: "54321"})
+	index.AddExcluded(MediaItem{Title: "Excluded", TmdbId: "999"})
+
+	tests := []struct {
+		name string
+		item MediaItem
+		want bool
+	}{
+		{"tmdb id", MediaItem{TmdbId: "12345"}, true},
+		{"imdb id", MediaItem{ImdbId: "TT0012345"}, true},
+		{"tvdb id", MediaItem{TvdbId: "54321"}, true},
+		{"tvdb id matching a tmdb id", MediaItem{TvdbId: "12345"}, false},
+		{"tmdb id matching a tvdb id", MediaItem{TmdbId: "54321"}, false},
+		{"excluded tmdb id", MediaItem{TmdbId: "999"}, true},
+		{"no ids", MediaItem{Title: "Movie"}, false},
+	}
+
+	for _, tt := range tests {
+		item := tt.item
+		if got := index.Contains(&item); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if index.Size() != 2 {
+		t.Errorf("Expected 2 items, got %d", index.Size())
+	}
+
+	if !index.IsExcluded(&MediaItem{TmdbId: "999"}) || index.IsExcluded(&MediaItem{TmdbId: "12345"}) {
+		t.Errorf("Expected only the excluded item to be reported as excluded")
+	}
+
+	// merge another index
+	other := NewMediaIndex()
+	other.Add(MediaItem{Title: "Movie", TmdbId: "12345"})
+	other.Add(MediaItem{Title: "Other", TmdbId: "67890"})
+	other.AddExcluded(MediaItem{Title: "Other Excluded", TvdbId: "888"})
+	index.Merge(other)
+
+	if index.Size() != 3 || index.ExcludedSize() != 2 {
+		t.Errorf("Expected 3 items and 2 excluded after merge, got %d and %d", index.Size(), index.ExcludedSize())
+	}
+}
@@ -8,23 +8,31 @@ import (
 )
 
 type MediaItem struct {
-	Provider  string
-	Endpoint  string
-	TvdbId    string
-	TmdbId    string
-	ImdbId    string
-	Slug      string
-	Title     string
-	Summary   string
-	Country   []string
-	Network   string
-	Date      time.Time
-	Year      int
-	Runtime   int
-	Status    string
-	Genres    []string
-	Languages []string
-	Character string
+	Provider      string
+	Endpoint      string
+	TvdbId        string
+	TmdbId        string
+	ImdbId        string
+	MbId          string // musicbrainz id
+	Slug          string
+	Title         string
+	Summary       string
+	Country       []string
+	Network       string
+	Date          time.Time
+	Year          int
+	Runtime       int
+	Status        string
+	Genres        []string
+	Languages     []string
+	Character     string
+	Rating        float64 // normalized to 0-10
+	Votes         int
+	Popularity    float64
+	Certification string
+	Episodes      int
+	Listeners     int // last.fm
+	Playcount     int // last.fm
 }
 
 type ExprEnv struct {
@@ -41,7 +49,7 @@ func GetExprEnv(media *MediaItem) *ExprEnv {
 }
 
 func (m *MediaItem) String() string {
-	if strings.Contains(m.Title, "("+strconv.Itoa(m.Year)+")") {
+	if m.Year == 0 || strings.Contains(m.Title, "("+strconv.Itoa(m.Year)+")") {
 		return m.Title
 	}
 
@@ -1,15 +1,104 @@
 package config
 
+import (
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// StringList is a list of names that can also be set as a comma separated string
+type StringList []string
+
 type Pvr struct {
 	Type            string
 	URL             string
-	ApiKey          string `mapstructure:"api_key"`
-	QualityProfile  string `mapstructure:"quality_profile"`
-	LanguageProfile string `mapstructure:"language_profile"`
-	RootFolder      string `mapstructure:"root_folder"`
+	ApiKey          string     `mapstructure:"api_key"`
+	QualityProfile  string     `mapstructure:"quality_profile"`
+	LanguageProfile string     `mapstructure:"language_profile"`
+	MetadataProfile string     `mapstructure:"metadata_profile"`
+	RootFolder      string     `mapstructure:"root_folder"`
+	AlsoCheck       StringList `mapstructure:"also_check"`
 	Filters         PvrFilters
+	AddOptions      PvrAddOptions `mapstructure:"add_options"`
+	Tags            []PvrTag
+	Routes          []PvrRoute
+}
+
+type PvrAddOptions struct {
+	Monitored           *bool
+	Search              *bool
+	MinimumAvailability string `mapstructure:"minimum_availability"`
+	Monitor             string
+	SeriesType          string `mapstructure:"series_type"`
+	SeasonFolder        *bool  `mapstructure:"season_folder"`
+	Approve             *bool
+	RequestUser         int `mapstructure:"request_user"`
+	Is4k                bool
 }
 
 type PvrFilters struct {
-	Ignores []string
+	Accepts []string
+	Ignores []PvrIgnore
+}
+
+type PvrIgnore struct {
+	When    string
+	Exclude bool
+}
+
+type PvrTag struct {
+	Name string
+	When string
+}
+
+type PvrRoute struct {
+	When                string
+	QualityProfile      string `mapstructure:"quality_profile"`
+	RootFolder          string `mapstructure:"root_folder"`
+	MinimumAvailability string `mapstructure:"minimum_availability"`
+	Monitored           *bool
+	Tags                []PvrTag
+}
+
+/* Private */
+
+func stringToStringListHookFunc() mapstructure.DecodeHookFuncType {
+	// allow name lists to be set as comma separated strings, e.g. pvr: radarr,radarr4k
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(StringList{}) {
+			return data, nil
+		}
+
+		list := make(StringList, 0)
+		for _, name := range strings.Split(data.(string), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				list = append(list, name)
+			}
+		}
+
+		return list, nil
+	}
+}
+
+func stringToPvrTagHookFunc() mapstructure.DecodeHookFuncType {
+	// allow tags to be set as plain names
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(PvrTag{}) {
+			return data, nil
+		}
+
+		return PvrTag{Name: data.(string)}, nil
+	}
+}
+
+func stringToPvrIgnoreHookFunc() mapstructure.DecodeHookFuncType {
+	// allow ignores to be set as plain expressions
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(PvrIgnore{}) {
+			return data, nil
+		}
+
+		return PvrIgnore{When: data.(string)}, nil
+	}
 }
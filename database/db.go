@@ -33,7 +33,7 @@ func Init(databaseFilePath string) error {
 	}
 
 	// migrate schema
-	return db.AutoMigrate(&ValidatedProviderItem{}, &ProviderItemMetadata{})
+	return db.AutoMigrate(&ProviderItemMetadata{}, &ProviderToken{})
 }
 
 func ShowUsing(databaseFilePath *string) {
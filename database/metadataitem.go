@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 )
 
@@ -13,10 +15,19 @@ func GetMetadataItem(provider string, itemId string) (*string, error) {
 		return nil, errors.WithMessage(err, "metadata item not found")
 	}
 
+	// has the item expired?
+	if existingItem.Expires.IsZero() || existingItem.Expires.Before(time.Now().UTC()) {
+		if err := db.Delete(&existingItem).Error; err != nil {
+			log.WithError(err).Errorf("Failed removing expired metadata item for %q: %q", provider, itemId)
+		}
+
+		return nil, errors.New("metadata item expired")
+	}
+
 	return &existingItem.Json, nil
 }
 
-func AddMetadataItem(provider string, itemId string, item interface{}) error {
+func AddMetadataItem(provider string, itemId string, item interface{}, ttl time.Duration) error {
 	// serialize item
 	itemJson, err := json.Marshal(item)
 	if err != nil {
@@ -28,9 +39,11 @@ func AddMetadataItem(provider string, itemId string, item interface{}) error {
 		Provider: provider,
 		Id:       itemId,
 		Json:     string(itemJson),
+		Expires:  time.Now().UTC().Add(ttl),
 	}
 
-	if err := db.Where(providerItem).Assign(providerItem).FirstOrCreate(&providerItem).Error; err != nil {
+	if err := db.Where(ProviderItemMetadata{Provider: provider, Id: itemId}).Assign(providerItem).
+		FirstOrCreate(&providerItem).Error; err != nil {
 		return errors.WithMessage(err, "failed storing metadata item")
 	}
 
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func GetProviderToken(provider string) (*ProviderToken, error) {
+	var existingToken ProviderToken
+
+	// does token exist?
+	if err := db.First(&existingToken, "provider = ?", provider).Error; err != nil {
+		return nil, errors.WithMessage(err, "provider token not found")
+	}
+
+	return &existingToken, nil
+}
+
+func SetProviderToken(provider string, accessToken string, refreshToken string, expires time.Time) error {
+	// insert or update token
+	providerToken := ProviderToken{
+		Provider:     provider,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Expires:      expires.UTC(),
+	}
+
+	if err := db.Where(ProviderToken{Provider: provider}).Assign(providerToken).
+		FirstOrCreate(&providerToken).Error; err != nil {
+		return errors.Wrapf(err, "failed storing provider token for %q", provider)
+	}
+
+	return nil
+}
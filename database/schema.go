@@ -2,14 +2,16 @@ package database
 
 import "time"
 
-type ValidatedProviderItem struct {
+type ProviderItemMetadata struct {
 	Provider string `gorm:"primary_key"`
 	Id       string `gorm:"primary_key"`
+	Json     string `gorm:"type:text"`
 	Expires  time.Time
 }
 
-type ProviderItemMetadata struct {
-	Provider string `gorm:"primary_key"`
-	Id       string `gorm:"primary_key"`
-	Json     string `gorm:"type:text"`
+type ProviderToken struct {
+	Provider     string `gorm:"primary_key"`
+	AccessToken  string
+	RefreshToken string
+	Expires      time.Time
 }
@@ -1,17 +1,26 @@
 package provider
 
-import "github.com/l3uddz/mediarr/config"
+import (
+	"context"
+
+	"github.com/l3uddz/mediarr/config"
+)
 
 type Interface interface {
-	Init(MediaType, map[string]string) error
+	Init(context.Context, MediaType, map[string]string) error
 	SetIgnoreExistingMediaItemFn(func(*config.MediaItem) bool)
+	SetIgnoreExcludedMediaItemFn(func(*config.MediaItem) bool)
 	SetAcceptMediaItemFn(func(*config.MediaItem) bool)
+	SetValidateMediaItemFn(func(context.Context, *config.MediaItem) bool)
 
 	GetShowsSearchTypes() []string
 	GetMoviesSearchTypes() []string
+	GetArtistsSearchTypes() []string
 	SupportsShowsSearchType(string) bool
 	SupportsMoviesSearchType(string) bool
+	SupportsArtistsSearchType(string) bool
 
-	GetShows(string, map[string]interface{}, map[string]string) (map[string]config.MediaItem, error)
-	GetMovies(string, map[string]interface{}, map[string]string) (map[string]config.MediaItem, error)
+	GetShows(context.Context, string, map[string]interface{}, map[string]string) (map[string]config.MediaItem, error)
+	GetMovies(context.Context, string, map[string]interface{}, map[string]string) (map[string]config.MediaItem, error)
+	GetArtists(context.Context, string, map[string]interface{}, map[string]string) (map[string]config.MediaItem, error)
 }
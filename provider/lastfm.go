@@ -0,0 +1,366 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/logger"
+	"github.com/l3uddz/mediarr/utils/lists"
+	"github.com/l3uddz/mediarr/utils/web"
+
+	"github.com/imroc/req"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/ratelimit"
+)
+
+/* Const */
+
+const (
+	LastFmRateLimit int = 5
+	LastFmPageSize  int = 50
+	LastFmMaxPages  int = 20
+)
+
+/* Struct */
+
+type LastFm struct {
+	log                       *logrus.Entry
+	cfg                       map[string]string
+	fnIgnoreExistingMediaItem func(*config.MediaItem) bool
+	fnIgnoreExcludedMediaItem func(*config.MediaItem) bool
+	fnAcceptMediaItem         func(*config.MediaItem) bool
+	fnValidateMediaItem       func(context.Context, *config.MediaItem) bool
+
+	apiUrl  string
+	apiKey  string
+	timeout int
+
+	reqRatelimit *ratelimit.Limiter
+	reqRetry     web.Retry
+
+	supportedShowsSearchTypes   []string
+	supportedMoviesSearchTypes  []string
+	supportedArtistsSearchTypes []string
+}
+
+type LastFmArtist struct {
+	Name      string `json:"name"`
+	Playcount string `json:"playcount"`
+	Listeners string `json:"listeners"`
+	Mbid      string `json:"mbid"`
+	Url       string `json:"url"`
+}
+
+type LastFmArtists struct {
+	Artist []LastFmArtist `json:"artist"`
+	Attr   struct {
+		Page       string `json:"page"`
+		TotalPages string `json:"totalPages"`
+	} `json:"@attr"`
+}
+
+type LastFmArtistsResponse struct {
+	Artists    *LastFmArtists `json:"artists"`
+	TopArtists *LastFmArtists `json:"topartists"`
+	Error      int            `json:"error"`
+	Message    string         `json:"message"`
+}
+
+/* Initializer */
+
+func NewLastFm() *LastFm {
+	return &LastFm{
+		log:               logger.GetLogger("lastfm"),
+		cfg:               nil,
+		fnAcceptMediaItem: nil,
+
+		apiUrl:  "https://ws.audioscrobbler.com/2.0/",
+		apiKey:  "",
+		timeout: providerDefaultTimeout,
+
+		supportedShowsSearchTypes:  []string{},
+		supportedMoviesSearchTypes: []string{},
+		supportedArtistsSearchTypes: []string{
+			SearchTypePopular,
+		},
+	}
+}
+
+/* Interface Implements */
+
+func (p *LastFm) Init(ctx context.Context, mediaType MediaType, cfg map[string]string) error {
+	// validate we support this media type
+	switch mediaType {
+	case Music:
+		break
+	default:
+		return errors.New("unsupported media type")
+	}
+
+	// set provider config
+	p.cfg = cfg
+
+	// validate api key set
+	if p.cfg == nil {
+		return errors.New("provider has no configuration data set")
+	} else if v, err := config.GetProviderSetting(cfg, "api_key"); err != nil {
+		return errors.New("provider requires an api_key to be configured")
+	} else {
+		p.apiKey = *v
+	}
+
+	// set ratelimiter
+	p.reqRatelimit = web.GetRateLimiter("lastfm", LastFmRateLimit)
+
+	// set default retry
+	p.reqRetry = providerDefaultRetry
+
+	return nil
+}
+
+func (p *LastFm) SetIgnoreExistingMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExistingMediaItem = fn
+}
+
+func (p *LastFm) SetIgnoreExcludedMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExcludedMediaItem = fn
+}
+
+func (p *LastFm) SetAcceptMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnAcceptMediaItem = fn
+}
+
+func (p *LastFm) SetValidateMediaItemFn(fn func(context.Context, *config.MediaItem) bool) {
+	p.fnValidateMediaItem = fn
+}
+
+func (p *LastFm) GetShowsSearchTypes() []string {
+	return p.supportedShowsSearchTypes
+}
+
+func (p *LastFm) SupportsShowsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedShowsSearchTypes, searchType, false)
+}
+
+func (p *LastFm) GetMoviesSearchTypes() []string {
+	return p.supportedMoviesSearchTypes
+}
+
+func (p *LastFm) SupportsMoviesSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedMoviesSearchTypes, searchType, false)
+}
+
+func (p *LastFm) GetArtistsSearchTypes() []string {
+	return p.supportedArtistsSearchTypes
+}
+
+func (p *LastFm) SupportsArtistsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedArtistsSearchTypes, searchType, false)
+}
+
+func (p *LastFm) GetShows(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	return nil, errors.New("unsupported media type")
+}
+
+func (p *LastFm) GetMovies(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	return nil, errors.New("unsupported media type")
+}
+
+func (p *LastFm) GetArtists(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+
+	switch searchType {
+	case SearchTypePopular:
+		return p.getPopularArtists(ctx, logic, params)
+	default:
+		break
+	}
+
+	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
+}
+
+/* Private - Sub-Implements */
+
+func (p *LastFm) getPopularArtists(ctx context.Context, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	// set request params
+	reqParams := req.Param{
+		"api_key": p.apiKey,
+		"format":  "json",
+		"limit":   LastFmPageSize,
+	}
+
+	// the top artists of a tag or country, otherwise the global chart
+	var genres, countries []string
+
+	switch {
+	case params["genre"] != "" && params["country"] != "":
+		return nil, errors.New("popular artists can be filtered by either a --genre or a --country")
+	case params["genre"] != "":
+		reqParams["method"] = "tag.gettopartists"
+		reqParams["tag"] = params["genre"]
+		genres = []string{strings.ToLower(params["genre"])}
+	case params["country"] != "":
+		reqParams["method"] = "geo.gettopartists"
+		reqParams["country"] = params["country"]
+		countries = []string{strings.ToLower(params["country"])}
+	default:
+		reqParams["method"] = "chart.gettopartists"
+	}
+
+	endpoint := reqParams["method"].(string)
+
+	// parse logic params
+	limit := 0
+	limitReached := false
+
+	if v := getLogicParam(logic, "limit"); v != nil {
+		limit = v.(int)
+	}
+
+	// fetch all page results
+	mediaItems := make(map[string]config.MediaItem)
+	mediaItemsSize := 0
+	ignoredItemsSize := 0
+	existingItemsSize := 0
+	excludedItemsSize := 0
+
+	page := 1
+
+	for {
+		// set params
+		reqParams["page"] = page
+
+		// send request
+		resp, err := web.GetResponse(ctx, web.GET, p.apiUrl, p.timeout, reqParams, &p.reqRetry, p.reqRatelimit)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed retrieving artists api response")
+		}
+
+		// validate response
+		if resp.Response().StatusCode != 200 {
+			web.DrainAndClose(resp.Response().Body)
+			return nil, fmt.Errorf("failed retrieving valid artists api response: %s", resp.Response().Status)
+		}
+
+		// decode response
+		var s LastFmArtistsResponse
+		if err := resp.ToJSON(&s); err != nil {
+			web.DrainAndClose(resp.Response().Body)
+			return nil, errors.WithMessage(err, "failed decoding artists api response")
+		}
+
+		web.DrainAndClose(resp.Response().Body)
+
+		artists := s.Artists
+		if artists == nil {
+			artists = s.TopArtists
+		}
+
+		if s.Error != 0 || artists == nil {
+			return nil, fmt.Errorf("failed retrieving valid artists api response: %d %s", s.Error, s.Message)
+		}
+
+		// process response
+		for _, item := range artists.Artist {
+			// stop when context is done
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			// skip artists without a musicbrainz id
+			if item.Mbid == "" {
+				p.log.Tracef("Ignoring, no MbId: %+v", item)
+				continue
+			}
+
+			// have we already pulled this item?
+			itemId := strings.ToLower(item.Mbid)
+			if _, exists := mediaItems[itemId]; exists {
+				continue
+			}
+
+			// init media item
+			listeners, _ := strconv.Atoi(item.Listeners)
+			playcount, _ := strconv.Atoi(item.Playcount)
+
+			mediaItem := config.MediaItem{
+				Provider:  "lastfm",
+				Endpoint:  endpoint,
+				MbId:      itemId,
+				Title:     item.Name,
+				Country:   countries,
+				Genres:    genres,
+				Listeners: listeners,
+				Playcount: playcount,
+			}
+
+			// has the pvr excluded this item?
+			if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+				excludedItemsSize++
+				continue
+			}
+
+			// does the pvr already have this item?
+			if p.fnIgnoreExistingMediaItem != nil && p.fnIgnoreExistingMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring existing: %+v", mediaItem)
+				existingItemsSize++
+				continue
+			}
+
+			// item passes ignore expressions and is a valid musicbrainz item?
+			if p.fnAcceptMediaItem != nil && !p.fnAcceptMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring: %+v", mediaItem)
+				ignoredItemsSize++
+				continue
+			} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, &mediaItem) {
+				p.log.Debugf("Ignoring, invalid MbId: %+v", mediaItem)
+				ignoredItemsSize++
+				continue
+			} else {
+				p.log.Debugf("Accepted: %+v", mediaItem)
+			}
+
+			// set media item
+			mediaItems[itemId] = mediaItem
+			mediaItemsSize++
+
+			// stop when limit reached
+			if isLimitReached(logic, limit, mediaItemsSize) {
+				// limit was supplied via cli and we have reached this limit
+				limitReached = true
+				break
+			}
+		}
+
+		totalPages, _ := strconv.Atoi(artists.Attr.TotalPages)
+
+		p.log.WithFields(logrus.Fields{
+			"page":     page,
+			"pages":    totalPages,
+			"accepted": mediaItemsSize,
+			"ignored":  ignoredItemsSize,
+			"existing": existingItemsSize,
+			"excluded": excludedItemsSize,
+		}).Info("Retrieved")
+
+		// loop logic
+		if limitReached {
+			// the limit has been reached for accepted items
+			break
+		}
+
+		// charts are very long, so only the first pages are checked
+		if page >= totalPages || page >= LastFmMaxPages {
+			break
+		} else {
+			page++
+		}
+	}
+
+	p.log.WithField("accepted_items", mediaItemsSize).Info("Retrieved media items")
+	return mediaItems, nil
+}
@@ -8,13 +8,22 @@ import (
 	"github.com/l3uddz/mediarr/utils/web"
 
 	"github.com/jpillora/backoff"
+	jsoniter "github.com/json-iterator/go"
 )
 
 var (
-	providerDefaultTimeout = 30
-	providerDefaultRetry   = web.Retry{
-		MaxAttempts:          6,
-		RetryableStatusCodes: []int{},
+	json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	providerDefaultTimeout     = 30
+	providerDefaultMetadataTtl = 168 * time.Hour
+	providerDefaultRetry       = web.Retry{
+		MaxAttempts: 6,
+		RetryableStatusCodes: []int{
+			429,
+			502,
+			503,
+			504,
+		},
 		Backoff: backoff.Backoff{
 			Jitter: true,
 			Min:    500 * time.Millisecond,
@@ -33,6 +42,10 @@ func Get(providerType string) (Interface, error) {
 		return NewTmdb(), nil
 	case "trakt":
 		return NewTrakt(), nil
+	case "simkl":
+		return NewSimkl(), nil
+	case "lastfm":
+		return NewLastFm(), nil
 	default:
 		break
 	}
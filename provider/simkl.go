@@ -0,0 +1,594 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/database"
+	"github.com/l3uddz/mediarr/logger"
+	"github.com/l3uddz/mediarr/utils/lists"
+	"github.com/l3uddz/mediarr/utils/web"
+
+	"github.com/imroc/req"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/ratelimit"
+)
+
+/* Const */
+
+const (
+	SimklRateLimit int = 2
+)
+
+/* Struct */
+
+type Simkl struct {
+	log                       *logrus.Entry
+	cfg                       map[string]string
+	fnIgnoreExistingMediaItem func(*config.MediaItem) bool
+	fnIgnoreExcludedMediaItem func(*config.MediaItem) bool
+	fnAcceptMediaItem         func(*config.MediaItem) bool
+	fnValidateMediaItem       func(context.Context, *config.MediaItem) bool
+
+	apiUrl      string
+	apiHeaders  req.Header
+	timeout     int
+	metadataTtl time.Duration
+
+	reqRatelimit *ratelimit.Limiter
+	reqRetry     web.Retry
+
+	supportedShowsSearchTypes   []string
+	supportedMoviesSearchTypes  []string
+	supportedArtistsSearchTypes []string
+}
+
+// SimklId is an id returned by the simkl api, which can be either a number or a string
+type SimklId string
+
+func (i *SimklId) UnmarshalJSON(b []byte) error {
+	v := strings.Trim(string(b), `"`)
+	if v == "null" {
+		v = ""
+	}
+
+	*i = SimklId(v)
+	return nil
+}
+
+type SimklIds struct {
+	Simkl   SimklId `json:"simkl"`
+	SimklId SimklId `json:"simkl_id"`
+	Slug    string  `json:"slug"`
+	Imdb    SimklId `json:"imdb"`
+	Tmdb    SimklId `json:"tmdb"`
+	Tvdb    SimklId `json:"tvdb"`
+}
+
+type SimklListItem struct {
+	Title     string   `json:"title"`
+	Year      int      `json:"year"`
+	AnimeType string   `json:"anime_type"`
+	Ids       SimklIds `json:"ids"`
+}
+
+type SimklItem struct {
+	Title         string   `json:"title"`
+	Year          int      `json:"year"`
+	Type          string   `json:"type"`
+	Ids           SimklIds `json:"ids"`
+	Released      string   `json:"released"`
+	FirstAired    string   `json:"first_aired"`
+	Runtime       int      `json:"runtime"`
+	Country       string   `json:"country"`
+	Network       string   `json:"network"`
+	Status        string   `json:"status"`
+	Overview      string   `json:"overview"`
+	Genres        []string `json:"genres"`
+	Certification string   `json:"certification"`
+	TotalEpisodes int      `json:"total_episodes"`
+	AnimeType     string   `json:"anime_type"`
+	Ratings       struct {
+		Simkl SimklRating `json:"simkl"`
+		Imdb  SimklRating `json:"imdb"`
+	} `json:"ratings"`
+}
+
+type SimklRating struct {
+	Rating float64 `json:"rating"`
+	Votes  int     `json:"votes"`
+}
+
+/* Initializer */
+
+func NewSimkl() *Simkl {
+	return &Simkl{
+		log:               logger.GetLogger("simkl"),
+		cfg:               nil,
+		fnAcceptMediaItem: nil,
+
+		apiUrl:      "https://api.simkl.com",
+		apiHeaders:  make(req.Header),
+		timeout:     providerDefaultTimeout,
+		metadataTtl: providerDefaultMetadataTtl,
+
+		supportedShowsSearchTypes: []string{
+			SearchTypeTrending,
+			SearchTypeBest,
+			SearchTypeAnimeAiring,
+		},
+		supportedMoviesSearchTypes: []string{
+			SearchTypeTrending,
+			SearchTypeBest,
+			SearchTypeAnimeAiring,
+		},
+		supportedArtistsSearchTypes: []string{},
+	}
+}
+
+/* Interface Implements */
+
+func (p *Simkl) Init(ctx context.Context, mediaType MediaType, cfg map[string]string) error {
+	// validate we support this media type
+	switch mediaType {
+	case Movie, Show:
+		break
+	default:
+		return errors.New("unsupported media type")
+	}
+
+	// set provider config
+	p.cfg = cfg
+
+	// validate client_id set
+	if p.cfg == nil {
+		return errors.New("provider has no configuration data set")
+	} else if v, err := config.GetProviderSetting(cfg, "client_id"); err != nil {
+		return errors.New("provider requires an client_id to be configured")
+	} else {
+		p.apiHeaders["simkl-api-key"] = *v
+	}
+
+	// set metadata ttl
+	if v, err := config.GetProviderSetting(cfg, "metadata_ttl"); err == nil {
+		ttl, err := time.ParseDuration(*v)
+		if err != nil {
+			return errors.Wrapf(err, "failed parsing metadata_ttl: %q", *v)
+		}
+
+		p.metadataTtl = ttl
+	}
+
+	// set ratelimiter
+	p.reqRatelimit = web.GetRateLimiter("simkl", SimklRateLimit)
+
+	// set default retry
+	p.reqRetry = providerDefaultRetry
+
+	return nil
+}
+
+func (p *Simkl) SetIgnoreExistingMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExistingMediaItem = fn
+}
+
+func (p *Simkl) SetIgnoreExcludedMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExcludedMediaItem = fn
+}
+
+func (p *Simkl) SetAcceptMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnAcceptMediaItem = fn
+}
+
+func (p *Simkl) SetValidateMediaItemFn(fn func(context.Context, *config.MediaItem) bool) {
+	p.fnValidateMediaItem = fn
+}
+
+func (p *Simkl) GetShowsSearchTypes() []string {
+	return p.supportedShowsSearchTypes
+}
+
+func (p *Simkl) SupportsShowsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedShowsSearchTypes, searchType, false)
+}
+
+func (p *Simkl) GetMoviesSearchTypes() []string {
+	return p.supportedMoviesSearchTypes
+}
+
+func (p *Simkl) SupportsMoviesSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedMoviesSearchTypes, searchType, false)
+}
+
+func (p *Simkl) GetArtistsSearchTypes() []string {
+	return p.supportedArtistsSearchTypes
+}
+
+func (p *Simkl) SupportsArtistsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedArtistsSearchTypes, searchType, false)
+}
+
+func (p *Simkl) GetShows(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+
+	switch searchType {
+	case SearchTypeTrending:
+		// get interval from query param (default to week if not provided)
+		interval, err := p.getIntervalFromQueryStr(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getItems(ctx, Show, fmt.Sprintf("/tv/trending/%s", interval), logic)
+	case SearchTypeBest:
+		// get filter from query param (default to all if not provided)
+		filter, err := p.getBestFilterFromQueryStr(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getItems(ctx, Show, fmt.Sprintf("/tv/best/%s", filter), logic)
+	case SearchTypeAnimeAiring:
+		return p.getItems(ctx, Show, "/anime/airing", logic)
+	default:
+		break
+	}
+
+	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
+}
+
+func (p *Simkl) GetMovies(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+
+	switch searchType {
+	case SearchTypeTrending:
+		// get interval from query param (default to week if not provided)
+		interval, err := p.getIntervalFromQueryStr(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getItems(ctx, Movie, fmt.Sprintf("/movies/trending/%s", interval), logic)
+	case SearchTypeBest:
+		// get filter from query param (default to all if not provided)
+		filter, err := p.getBestFilterFromQueryStr(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getItems(ctx, Movie, fmt.Sprintf("/movies/best/%s", filter), logic)
+	case SearchTypeAnimeAiring:
+		return p.getItems(ctx, Movie, "/anime/airing", logic)
+	default:
+		break
+	}
+
+	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
+}
+
+func (p *Simkl) GetArtists(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	return nil, errors.New("unsupported media type")
+}
+
+/* Private - Sub-Implements */
+
+func (p *Simkl) getIntervalFromQueryStr(params map[string]string) (string, error) {
+	queryStr, ok := params["query"]
+
+	if ok && queryStr != "" {
+		switch queryStr {
+		case "today", "week", "month":
+			return queryStr, nil
+		default:
+			return "", errors.New("trending search defaults to week, valid query params: today, week, month")
+		}
+	}
+
+	return "week", nil
+}
+
+func (p *Simkl) getBestFilterFromQueryStr(params map[string]string) (string, error) {
+	queryStr, ok := params["query"]
+
+	if ok && queryStr != "" {
+		switch queryStr {
+		case "all", "year", "month", "voted", "watched":
+			return queryStr, nil
+		default:
+			return "", errors.New("best search defaults to all, valid query params: all, year, month, voted, watched")
+		}
+	}
+
+	return "all", nil
+}
+
+func (p *Simkl) getItemDetails(ctx context.Context, itemType string, simklId string) (*SimklItem, error) {
+	// check database for this item
+	metadataType := "simkl_" + itemType
+	existingItemJson, err := database.GetMetadataItem(metadataType, simklId)
+	if err == nil && existingItemJson != nil {
+		// item was found in database, unmarshal
+		var n SimklItem
+		if err := json.Unmarshal([]byte(*existingItemJson), &n); err != nil {
+			p.log.WithError(err).Errorf("Failed decoding metadata stored in database for simkl id: %q", simklId)
+		} else {
+			return &n, nil
+		}
+	}
+
+	// set request params
+	params := req.Param{
+		"extended": "full",
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, itemType, simklId), p.timeout, p.apiHeaders,
+		params, &p.reqRetry, p.reqRatelimit)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving item details api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid item details api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s SimklItem
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding item details api response")
+	}
+
+	// add item to database
+	if err := database.AddMetadataItem(metadataType, simklId, s, p.metadataTtl); err != nil {
+		p.log.WithError(err).Errorf("Failed adding metadata item to database for simkl id: %q", simklId)
+	}
+
+	return &s, nil
+}
+
+func (p *Simkl) getItems(ctx context.Context, mediaType MediaType, endpoint string, logic map[string]interface{}) (map[string]config.MediaItem, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, p.apiHeaders,
+		&p.reqRetry, p.reqRatelimit)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving items api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid items api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []SimklListItem
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding items api response")
+	}
+
+	// parse logic params
+	limit := 0
+
+	if v := getLogicParam(logic, "limit"); v != nil {
+		limit = v.(int)
+	}
+
+	// determine details endpoint
+	itemType := "tv"
+	isAnime := strings.HasPrefix(endpoint, "/anime/")
+
+	switch {
+	case isAnime:
+		itemType = "anime"
+	case mediaType == Movie:
+		itemType = "movies"
+	}
+
+	// process response
+	mediaItems := make(map[string]config.MediaItem)
+	pulledMediaItems := config.NewMediaIndex()
+	mediaItemsSize := 0
+	ignoredItemsSize := 0
+	existingItemsSize := 0
+	excludedItemsSize := 0
+
+	for _, item := range s {
+		// stop when context is done
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// skip anime of the wrong type
+		if isAnime && (mediaType == Movie) != strings.EqualFold(item.AnimeType, "movie") {
+			continue
+		}
+
+		// skip invalid items
+		simklId := string(item.Ids.Simkl)
+		if simklId == "" {
+			simklId = string(item.Ids.SimklId)
+		}
+
+		if simklId == "" {
+			continue
+		}
+
+		// check the ids of the list item before retrieving details
+		listItem := config.MediaItem{
+			Provider: "simkl",
+			Endpoint: endpoint,
+			TvdbId:   string(item.Ids.Tvdb),
+			TmdbId:   parseSimklTmdbId(item.Ids.Tmdb),
+			ImdbId:   string(item.Ids.Imdb),
+			Slug:     item.Ids.Slug,
+			Title:    item.Title,
+			Year:     item.Year,
+		}
+
+		if pulledMediaItems.Contains(&listItem) {
+			continue
+		}
+
+		if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&listItem) {
+			p.log.Debugf("Ignoring excluded: %+v", listItem)
+			excludedItemsSize++
+			continue
+		}
+
+		if p.fnIgnoreExistingMediaItem != nil && p.fnIgnoreExistingMediaItem(&listItem) {
+			p.log.Debugf("Ignoring existing: %+v", listItem)
+			existingItemsSize++
+			continue
+		}
+
+		// retrieve item details
+		details, err := p.getItemDetails(ctx, itemType, simklId)
+		if err != nil {
+			p.log.WithError(err).Tracef("Failed retrieving details for item: %+v", item)
+			continue
+		}
+
+		// translate item
+		mediaItem, err := p.translateItem(mediaType, endpoint, details)
+		if err != nil {
+			p.log.WithError(err).Tracef("Failed translating item: %+v", details)
+			continue
+		}
+
+		// have we already pulled this item?
+		itemId := mediaItem.TmdbId
+		if mediaType == Show {
+			itemId = mediaItem.TvdbId
+		}
+
+		if pulledMediaItems.Contains(mediaItem) {
+			continue
+		}
+
+		// has the pvr excluded this item?
+		if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(mediaItem) {
+			p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+			excludedItemsSize++
+			continue
+		}
+
+		// does the pvr already have this item?
+		if p.fnIgnoreExistingMediaItem != nil && p.fnIgnoreExistingMediaItem(mediaItem) {
+			p.log.Debugf("Ignoring existing: %+v", mediaItem)
+			existingItemsSize++
+			continue
+		}
+
+		// item passes ignore expressions and is a valid item?
+		if p.fnAcceptMediaItem != nil && !p.fnAcceptMediaItem(mediaItem) {
+			p.log.Debugf("Ignoring: %+v", mediaItem)
+			ignoredItemsSize++
+			continue
+		} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, mediaItem) {
+			p.log.Debugf("Ignoring, invalid id: %+v", mediaItem)
+			ignoredItemsSize++
+			continue
+		} else {
+			p.log.Debugf("Accepted: %+v", mediaItem)
+		}
+
+		// set item
+		mediaItems[itemId] = *mediaItem
+		pulledMediaItems.Add(*mediaItem)
+		mediaItemsSize++
+
+		// stop when limit reached
+		if isLimitReached(logic, limit, mediaItemsSize) {
+			// limit was supplied via cli and we have reached this limit
+			break
+		}
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"accepted": mediaItemsSize,
+		"ignored":  ignoredItemsSize,
+		"existing": existingItemsSize,
+		"excluded": excludedItemsSize,
+	}).Info("Retrieved media items")
+	return mediaItems, nil
+}
+
+func (p *Simkl) translateItem(mediaType MediaType, endpoint string, item *SimklItem) (*config.MediaItem, error) {
+	// validate required ids
+	tmdbId := parseSimklTmdbId(item.Ids.Tmdb)
+
+	switch {
+	case mediaType == Movie && tmdbId == "":
+		return nil, errors.New("item has no tmdb id")
+	case mediaType == Show && item.Ids.Tvdb == "":
+		return nil, errors.New("item has no tvdb id")
+	}
+
+	// parse item date
+	dateStr := item.Released
+	if dateStr == "" {
+		dateStr = item.FirstAired
+	}
+
+	date, err := time.Parse(time.RFC3339, dateStr)
+	if err != nil {
+		if date, err = time.Parse("2006-01-02", dateStr); err != nil {
+			return nil, errors.WithMessage(err, "failed parsing release date")
+		}
+	}
+
+	// parse item genres (trakt style slugs)
+	var genres []string
+	for _, genre := range item.Genres {
+		genres = append(genres, strings.ReplaceAll(strings.ToLower(genre), " ", "-"))
+	}
+
+	// parse item country
+	var countries []string
+	if item.Country != "" {
+		countries = append(countries, strings.ToLower(item.Country))
+	}
+
+	// prefer simkl ratings, falling back to imdb
+	rating := item.Ratings.Simkl
+	if rating.Votes == 0 {
+		rating = item.Ratings.Imdb
+	}
+
+	return &config.MediaItem{
+		Provider:      "simkl",
+		Endpoint:      endpoint,
+		TvdbId:        string(item.Ids.Tvdb),
+		TmdbId:        tmdbId,
+		ImdbId:        string(item.Ids.Imdb),
+		Slug:          item.Ids.Slug,
+		Title:         item.Title,
+		Summary:       item.Overview,
+		Country:       countries,
+		Network:       item.Network,
+		Date:          date,
+		Year:          date.Year(),
+		Runtime:       item.Runtime,
+		Status:        item.Status,
+		Genres:        genres,
+		Languages:     nil,
+		Rating:        rating.Rating,
+		Votes:         rating.Votes,
+		Certification: item.Certification,
+		Episodes:      item.TotalEpisodes,
+	}, nil
+}
+
+func parseSimklTmdbId(id SimklId) string {
+	// simkl sometimes returns non numeric tmdb ids
+	if _, err := strconv.Atoi(string(id)); err != nil {
+		return ""
+	}
+
+	return string(id)
+}
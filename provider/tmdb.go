@@ -1,11 +1,14 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/database"
 	"github.com/l3uddz/mediarr/logger"
 	"github.com/l3uddz/mediarr/utils/lists"
 	"github.com/l3uddz/mediarr/utils/web"
@@ -28,19 +31,23 @@ type Tmdb struct {
 	log                       *logrus.Entry
 	cfg                       map[string]string
 	fnIgnoreExistingMediaItem func(*config.MediaItem) bool
+	fnIgnoreExcludedMediaItem func(*config.MediaItem) bool
 	fnAcceptMediaItem         func(*config.MediaItem) bool
+	fnValidateMediaItem       func(context.Context, *config.MediaItem) bool
 
-	apiUrl  string
-	apiKey  string
-	timeout int
+	apiUrl      string
+	apiKey      string
+	timeout     int
+	metadataTtl time.Duration
 
 	reqRatelimit *ratelimit.Limiter
 	reqRetry     web.Retry
 
 	genres map[int]string
 
-	supportedShowsSearchTypes  []string
-	supportedMoviesSearchTypes []string
+	supportedShowsSearchTypes   []string
+	supportedMoviesSearchTypes  []string
+	supportedArtistsSearchTypes []string
 }
 
 type TmdbGenre struct {
@@ -56,6 +63,7 @@ type TmdbMoviesResponse struct {
 	Results []struct {
 		Popularity       float64 `json:"popularity"`
 		VoteCount        int     `json:"vote_count"`
+		VoteAverage      float64 `json:"vote_average"`
 		Video            bool    `json:"video"`
 		PosterPath       string  `json:"poster_path"`
 		ID               int     `json:"id"`
@@ -77,6 +85,37 @@ type TmdbMoviesResponse struct {
 	TotalPages int `json:"total_pages"`
 }
 
+type TmdbShowsResponse struct {
+	Results []struct {
+		Popularity       float64  `json:"popularity"`
+		VoteCount        int      `json:"vote_count"`
+		VoteAverage      float64  `json:"vote_average"`
+		PosterPath       string   `json:"poster_path"`
+		ID               int      `json:"id"`
+		BackdropPath     string   `json:"backdrop_path"`
+		OriginalLanguage string   `json:"original_language"`
+		OriginalName     string   `json:"original_name"`
+		OriginCountry    []string `json:"origin_country"`
+		GenreIds         []int    `json:"genre_ids"`
+		Name             string   `json:"name"`
+		Overview         string   `json:"overview"`
+		FirstAirDate     string   `json:"first_air_date"`
+	} `json:"results"`
+	Page         int `json:"page"`
+	TotalResults int `json:"total_results"`
+	TotalPages   int `json:"total_pages"`
+}
+
+type TmdbShowExternalIdsResponse struct {
+	ID          int    `json:"id"`
+	ImdbID      string `json:"imdb_id"`
+	TvdbID      int    `json:"tvdb_id"`
+	TvrageID    int    `json:"tvrage_id"`
+	FacebookID  string `json:"facebook_id"`
+	InstagramID string `json:"instagram_id"`
+	TwitterID   string `json:"twitter_id"`
+}
+
 type TmdbMovieDetailsResponse struct {
 	Adult               bool        `json:"adult"`
 	BackdropPath        string      `json:"backdrop_path"`
@@ -121,28 +160,41 @@ func NewTmdb() *Tmdb {
 		cfg:               nil,
 		fnAcceptMediaItem: nil,
 
-		apiUrl:  "https://api.themoviedb.org/3",
-		apiKey:  "",
-		timeout: providerDefaultTimeout,
+		apiUrl:      "https://api.themoviedb.org/3",
+		apiKey:      "",
+		timeout:     providerDefaultTimeout,
+		metadataTtl: providerDefaultMetadataTtl,
 
 		genres: make(map[int]string),
 
-		supportedShowsSearchTypes: []string{},
+		supportedShowsSearchTypes: []string{
+			SearchTypePopular,
+			SearchTypeTopRated,
+			SearchTypeOnTheAir,
+			SearchTypeAiringToday,
+			SearchTypeTrending,
+			SearchTypeDiscover,
+		},
 		supportedMoviesSearchTypes: []string{
 			SearchTypeNow,
 			SearchTypeUpcoming,
 			SearchTypePopular,
+			SearchTypeDiscover,
 		},
+		supportedArtistsSearchTypes: []string{},
 	}
 }
 
 /* Interface Implements */
 
-func (p *Tmdb) Init(mediaType MediaType, cfg map[string]string) error {
+func (p *Tmdb) Init(ctx context.Context, mediaType MediaType, cfg map[string]string) error {
 	// validate we support this media type
+	genreType := ""
 	switch mediaType {
 	case Movie:
-		break
+		genreType = "movie"
+	case Show:
+		genreType = "tv"
 	default:
 		return errors.New("unsupported media type")
 	}
@@ -159,6 +211,16 @@ func (p *Tmdb) Init(mediaType MediaType, cfg map[string]string) error {
 		p.apiKey = *v
 	}
 
+	// set metadata ttl
+	if v, err := config.GetProviderSetting(cfg, "metadata_ttl"); err == nil {
+		ttl, err := time.ParseDuration(*v)
+		if err != nil {
+			return errors.Wrapf(err, "failed parsing metadata_ttl: %q", *v)
+		}
+
+		p.metadataTtl = ttl
+	}
+
 	// set ratelimiter
 	p.reqRatelimit = web.GetRateLimiter("tmdb", TmdbRateLimit)
 
@@ -166,7 +228,7 @@ func (p *Tmdb) Init(mediaType MediaType, cfg map[string]string) error {
 	p.reqRetry = providerDefaultRetry
 
 	// load genres
-	if err := p.loadGenres(); err != nil {
+	if err := p.loadGenres(ctx, genreType); err != nil {
 		return err
 	}
 
@@ -177,10 +239,18 @@ func (p *Tmdb) SetIgnoreExistingMediaItemFn(fn func(*config.MediaItem) bool) {
 	p.fnIgnoreExistingMediaItem = fn
 }
 
+func (p *Tmdb) SetIgnoreExcludedMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExcludedMediaItem = fn
+}
+
 func (p *Tmdb) SetAcceptMediaItemFn(fn func(*config.MediaItem) bool) {
 	p.fnAcceptMediaItem = fn
 }
 
+func (p *Tmdb) SetValidateMediaItemFn(fn func(context.Context, *config.MediaItem) bool) {
+	p.fnValidateMediaItem = fn
+}
+
 func (p *Tmdb) GetShowsSearchTypes() []string {
 	return p.supportedShowsSearchTypes
 }
@@ -197,19 +267,53 @@ func (p *Tmdb) SupportsMoviesSearchType(searchType string) bool {
 	return lists.StringListContains(p.supportedMoviesSearchTypes, searchType, false)
 }
 
-func (p *Tmdb) GetShows(searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
-	return nil, errors.New("unsupported media type")
+func (p *Tmdb) GetArtistsSearchTypes() []string {
+	return p.supportedArtistsSearchTypes
+}
+
+func (p *Tmdb) SupportsArtistsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedArtistsSearchTypes, searchType, false)
+}
+
+func (p *Tmdb) GetShows(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+
+	switch searchType {
+	case SearchTypePopular:
+		return p.getShows(ctx, "/tv/popular", logic, params)
+	case SearchTypeTopRated:
+		return p.getShows(ctx, "/tv/top_rated", logic, params)
+	case SearchTypeOnTheAir:
+		return p.getShows(ctx, "/tv/on_the_air", logic, params)
+	case SearchTypeAiringToday:
+		return p.getShows(ctx, "/tv/airing_today", logic, params)
+	case SearchTypeTrending:
+		// get window from query param (default to week if not provided)
+		window, err := p.getTimeWindowFromQueryStr(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getShows(ctx, fmt.Sprintf("/trending/tv/%s", window), logic, params)
+	case SearchTypeDiscover:
+		return p.getShows(ctx, "/discover/tv", logic, params)
+	default:
+		break
+	}
+
+	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
 }
 
-func (p *Tmdb) GetMovies(searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *Tmdb) GetMovies(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 
 	switch searchType {
 	case SearchTypeNow:
-		return p.getMovies("/movie/now_playing", logic, params)
+		return p.getMovies(ctx, "/movie/now_playing", logic, params)
 	case SearchTypeUpcoming:
-		return p.getMovies("/movie/upcoming", logic, params)
+		return p.getMovies(ctx, "/movie/upcoming", logic, params)
 	case SearchTypePopular:
-		return p.getMovies("/movie/popular", logic, params)
+		return p.getMovies(ctx, "/movie/popular", logic, params)
+	case SearchTypeDiscover:
+		return p.getMovies(ctx, "/discover/movie", logic, params)
 	default:
 		break
 	}
@@ -217,9 +321,33 @@ func (p *Tmdb) GetMovies(searchType string, logic map[string]interface{}, params
 	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
 }
 
+func (p *Tmdb) GetArtists(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	return nil, errors.New("unsupported media type")
+}
+
 /* Private - Sub-Implements */
 
-func (p *Tmdb) getRequestParams(params map[string]string) req.Param {
+func (p *Tmdb) getTimeWindowFromQueryStr(params map[string]string) (string, error) {
+	queryStr, ok := params["query"]
+
+	if ok && queryStr != "" {
+		switch queryStr {
+		case "day", "week":
+			return queryStr, nil
+		default:
+			return "", errors.New("trending search defaults to week, valid query params: day, week")
+		}
+	}
+
+	return "week", nil
+}
+
+func (p *Tmdb) getRequestParams(ctx context.Context, endpoint string, params map[string]string) (req.Param, error) {
+	// discover endpoints support the full filter set
+	if strings.HasPrefix(endpoint, "/discover/") {
+		return p.getDiscoverRequestParams(ctx, endpoint, params)
+	}
+
 	// set request params
 	reqParams := req.Param{
 		"api_key": p.apiKey,
@@ -242,17 +370,155 @@ func (p *Tmdb) getRequestParams(params map[string]string) req.Param {
 		}
 	}
 
-	return reqParams
+	return reqParams, nil
+}
+
+func (p *Tmdb) getDiscoverRequestParams(ctx context.Context, endpoint string, params map[string]string) (req.Param, error) {
+	// set request params
+	reqParams := req.Param{
+		"api_key":       p.apiKey,
+		"sort_by":       "popularity.desc",
+		"include_adult": false,
+	}
+
+	// determine date fields for this media type
+	isShow := endpoint == "/discover/tv"
+	yearField, releaseField := "primary_release_date", "release_date"
+	if isShow {
+		yearField, releaseField = "first_air_date", "air_date"
+	}
+
+	for k, v := range params {
+		// skip empty params
+		if v == "" {
+			continue
+		}
+
+		switch k {
+		case "country":
+			reqParams["with_origin_country"] = strings.ToUpper(strings.ReplaceAll(v, ",", "|"))
+		case "language":
+			reqParams["with_original_language"] = strings.ReplaceAll(v, ",", "|")
+		case "genre":
+			genreIds, err := p.getGenreIds(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+			reqParams["with_genres"] = strings.Join(genreIds, "|")
+		case "year":
+			from, to, isRange := getRangeParam(v)
+			if !isRange {
+				to = from
+			}
+			if from != "" {
+				reqParams[yearField+".gte"] = from + "-01-01"
+			}
+			if to != "" {
+				reqParams[yearField+".lte"] = to + "-12-31"
+			}
+		case "released":
+			// release windows are separated by a colon, e.g. 2020-01-01:2020-06-30
+			from, to := v, ""
+			if idx := strings.Index(v, ":"); idx >= 0 {
+				from, to = v[:idx], v[idx+1:]
+			}
+			if from != "" {
+				reqParams[releaseField+".gte"] = from
+			}
+			if to != "" {
+				reqParams[releaseField+".lte"] = to
+			}
+		case "rating":
+			from, to, _ := getRangeParam(v)
+			if from != "" {
+				reqParams["vote_average.gte"] = from
+			}
+			if to != "" {
+				reqParams["vote_average.lte"] = to
+			}
+		case "votes":
+			reqParams["vote_count.gte"] = v
+		case "status":
+			if !isShow {
+				break
+			}
+
+			statusIds, err := getTmdbShowStatusIds(v)
+			if err != nil {
+				return nil, err
+			}
+			reqParams["with_status"] = strings.Join(statusIds, "|")
+		case "sort":
+			reqParams["sort_by"] = v
+
+		default:
+			break
+		}
+	}
+
+	return reqParams, nil
+}
+
+func (p *Tmdb) getGenreIds(ctx context.Context, genres string) ([]string, error) {
+	genreIds := make([]string, 0)
+
+	for _, genre := range strings.Split(genres, ",") {
+		// normalize genre, e.g. science-fiction => science fiction
+		genre = strings.TrimSpace(strings.ReplaceAll(genre, "-", " "))
+		if genre == "" {
+			continue
+		}
+
+		found := false
+		for genreId, genreName := range p.genres {
+			if strings.EqualFold(genreName, genre) || strings.EqualFold(strconv.Itoa(genreId), genre) {
+				genreIds = append(genreIds, strconv.Itoa(genreId))
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unsupported genre: %q", genre)
+		}
+	}
+
+	return genreIds, nil
 }
 
-func (p *Tmdb) loadGenres() error {
+func getTmdbShowStatusIds(statuses string) ([]string, error) {
+	statusIds := make([]string, 0)
+
+	for _, status := range strings.Split(statuses, ",") {
+		switch strings.ToLower(strings.TrimSpace(status)) {
+		case "returning series", "returning":
+			statusIds = append(statusIds, "0")
+		case "planned":
+			statusIds = append(statusIds, "1")
+		case "in production":
+			statusIds = append(statusIds, "2")
+		case "ended":
+			statusIds = append(statusIds, "3")
+		case "canceled", "cancelled":
+			statusIds = append(statusIds, "4")
+		case "pilot":
+			statusIds = append(statusIds, "5")
+		default:
+			return nil, fmt.Errorf("unsupported status: %q", status)
+		}
+	}
+
+	return statusIds, nil
+}
+
+func (p *Tmdb) loadGenres(ctx context.Context, genreType string) error {
 	// set request params
 	params := req.Param{
 		"api_key": p.apiKey,
 	}
 
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "genre", "movie", "list"), p.timeout, params,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "genre", genreType, "list"), p.timeout, params,
 		&providerDefaultTimeout, p.reqRatelimit)
 	if err != nil {
 		return errors.WithMessage(err, "failed retrieving genres api response")
@@ -279,55 +545,102 @@ func (p *Tmdb) loadGenres() error {
 	return nil
 }
 
-//
-//func (p *Tmdb) getMovieDetails(tmdbId string) (*TmdbMovieDetailsResponse, error) {
-//	// check database for this item
-//	existingItemJson, err := database.GetMetadataItem("tmdb", tmdbId)
-//	if err == nil && existingItemJson != nil {
-//		// item was found in database, unmarshal
-//		var n TmdbMovieDetailsResponse
-//		if err := json.Unmarshal([]byte(*existingItemJson), &n); err != nil {
-//			p.log.WithError(err).Errorf("Failed decoding metadata stored in database for tmdb id: %q", tmdbId)
-//		} else {
-//			return &n, nil
-//		}
-//	}
-//
-//	// set request params
-//	params := req.Param{
-//		"api_key": p.apiKey,
-//	}
-//
-//	// send request
-//	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "movie", tmdbId), p.timeout, params,
-//		&providerDefaultTimeout, p.reqRatelimit)
-//	if err != nil {
-//		return nil, errors.WithMessage(err, "failed retrieving movie details api response")
-//	}
-//	defer web.DrainAndClose(resp.Response().Body)
-//
-//	// validate response
-//	if resp.Response().StatusCode != 200 {
-//		return nil, fmt.Errorf("failed retrieving valid movie details api response: %s", resp.Response().Status)
-//	}
-//
-//	// decode response
-//	var s TmdbMovieDetailsResponse
-//	if err := resp.ToJSON(&s); err != nil {
-//		return nil, errors.WithMessage(err, "failed decoding movie details api response")
-//	}
-//
-//	// add item to database
-//	if err := database.AddMetadataItem("tmdb", tmdbId, s); err != nil {
-//		logrus.WithError(err).Errorf("Failed adding metadata item to database for tmdb id: %q", tmdbId)
-//	}
-//
-//	return &s, nil
-//}
-
-func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *Tmdb) getShowExternalIds(ctx context.Context, tmdbId string) (*TmdbShowExternalIdsResponse, error) {
+	// check database for this item
+	existingItemJson, err := database.GetMetadataItem("tmdb_tv_external_ids", tmdbId)
+	if err == nil && existingItemJson != nil {
+		// item was found in database, unmarshal
+		var n TmdbShowExternalIdsResponse
+		if err := json.Unmarshal([]byte(*existingItemJson), &n); err != nil {
+			p.log.WithError(err).Errorf("Failed decoding external ids stored in database for tmdb id: %q", tmdbId)
+		} else {
+			return &n, nil
+		}
+	}
+
 	// set request params
-	reqParams := p.getRequestParams(params)
+	params := req.Param{
+		"api_key": p.apiKey,
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "tv", tmdbId, "external_ids"), p.timeout, params,
+		&p.reqRetry, p.reqRatelimit)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving show external ids api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid show external ids api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s TmdbShowExternalIdsResponse
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding show external ids api response")
+	}
+
+	// add item to database
+	if err := database.AddMetadataItem("tmdb_tv_external_ids", tmdbId, s, p.metadataTtl); err != nil {
+		p.log.WithError(err).Errorf("Failed adding external ids to database for tmdb id: %q", tmdbId)
+	}
+
+	return &s, nil
+}
+
+func (p *Tmdb) getMovieDetails(ctx context.Context, tmdbId string) (*TmdbMovieDetailsResponse, error) {
+	// check database for this item
+	existingItemJson, err := database.GetMetadataItem("tmdb", tmdbId)
+	if err == nil && existingItemJson != nil {
+		// item was found in database, unmarshal
+		var n TmdbMovieDetailsResponse
+		if err := json.Unmarshal([]byte(*existingItemJson), &n); err != nil {
+			p.log.WithError(err).Errorf("Failed decoding metadata stored in database for tmdb id: %q", tmdbId)
+		} else {
+			return &n, nil
+		}
+	}
+
+	// set request params
+	params := req.Param{
+		"api_key": p.apiKey,
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "movie", tmdbId), p.timeout, params,
+		&p.reqRetry, p.reqRatelimit)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving movie details api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid movie details api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s TmdbMovieDetailsResponse
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding movie details api response")
+	}
+
+	// add item to database
+	if err := database.AddMetadataItem("tmdb", tmdbId, s, p.metadataTtl); err != nil {
+		p.log.WithError(err).Errorf("Failed adding metadata item to database for tmdb id: %q", tmdbId)
+	}
+
+	return &s, nil
+}
+
+func (p *Tmdb) getMovies(ctx context.Context, endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	// set request params
+	reqParams, err := p.getRequestParams(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
 
 	p.log.Tracef("Request params: %+v", params)
 
@@ -344,6 +657,7 @@ func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params m
 	mediaItemsSize := 0
 	ignoredItemsSize := 0
 	existingItemsSize := 0
+	excludedItemsSize := 0
 
 	page := 1
 
@@ -352,7 +666,7 @@ func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params m
 		reqParams["page"] = page
 
 		// send request
-		resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, reqParams, &p.reqRetry,
+		resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, reqParams, &p.reqRetry,
 			p.reqRatelimit)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed retrieving movies api response")
@@ -375,6 +689,11 @@ func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params m
 
 		// process response
 		for _, item := range s.Results {
+			// stop when context is done
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
 			// skip this item?
 			if item.Adult || item.Video {
 				continue
@@ -403,19 +722,29 @@ func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params m
 
 			// init media item
 			mediaItem := config.MediaItem{
-				Provider:  "tmdb",
-				Endpoint:  endpoint,
-				TvdbId:    "",
-				TmdbId:    itemId,
-				ImdbId:    "",
-				Title:     item.Title,
-				Summary:   item.Overview,
-				Network:   "",
-				Date:      date,
-				Year:      date.Year(),
-				Runtime:   0,
-				Genres:    genres,
-				Languages: []string{item.OriginalLanguage},
+				Provider:   "tmdb",
+				Endpoint:   endpoint,
+				TvdbId:     "",
+				TmdbId:     itemId,
+				ImdbId:     "",
+				Title:      item.Title,
+				Summary:    item.Overview,
+				Network:    "",
+				Date:       date,
+				Year:       date.Year(),
+				Runtime:    0,
+				Genres:     genres,
+				Languages:  []string{item.OriginalLanguage},
+				Rating:     item.VoteAverage,
+				Votes:      item.VoteCount,
+				Popularity: item.Popularity,
+			}
+
+			// has the pvr excluded this item?
+			if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+				excludedItemsSize++
+				continue
 			}
 
 			// does the pvr already have this item?
@@ -426,34 +755,247 @@ func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params m
 			}
 
 			// retrieve additional movie details
-			//movieDetails, err := p.getMovieDetails(itemId)
-			//if err != nil {
-			//	// skip this item as it failed tmdb id validation
-			//	p.log.Debugf("Ignoring, invalid TmdbId: %+v", mediaItem)
-			//	ignoredItemsSize++
-			//	continue
-			//} else {
-			//	// set additional movie details
-			//	mediaItem.Runtime = movieDetails.Runtime
-			//	mediaItem.ImdbId = movieDetails.ImdbID
-			//	mediaItem.Status = movieDetails.Status
-			//}
-
-			// item passes ignore expressions?
+			movieDetails, err := p.getMovieDetails(ctx, itemId)
+			if err != nil {
+				// skip this item as it failed tmdb id validation
+				p.log.WithError(err).Debugf("Ignoring, invalid TmdbId: %+v", mediaItem)
+				ignoredItemsSize++
+				continue
+			} else {
+				// set additional movie details
+				mediaItem.Runtime = movieDetails.Runtime
+				mediaItem.ImdbId = movieDetails.ImdbID
+				mediaItem.Status = movieDetails.Status
+
+				for _, country := range movieDetails.ProductionCountries {
+					mediaItem.Country = append(mediaItem.Country, strings.ToLower(country.Iso31661))
+				}
+			}
+
+			// item passes ignore expressions and is a valid tmdb item?
+			if p.fnAcceptMediaItem != nil && !p.fnAcceptMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring: %+v", mediaItem)
+				ignoredItemsSize++
+				continue
+			} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, &mediaItem) {
+				p.log.Debugf("Ignoring, invalid TmdbId: %+v", mediaItem)
+				ignoredItemsSize++
+				continue
+			} else {
+				p.log.Debugf("Accepted: %+v", mediaItem)
+			}
+
+			// set media item
+			mediaItems[itemId] = mediaItem
+			mediaItemsSize++
+
+			// stop when limit reached
+			if isLimitReached(logic, limit, mediaItemsSize) {
+				// limit was supplied via cli and we have reached this limit
+				limitReached = true
+				break
+			}
+		}
+
+		p.log.WithFields(logrus.Fields{
+			"page":     page,
+			"pages":    s.TotalPages,
+			"accepted": mediaItemsSize,
+			"ignored":  ignoredItemsSize,
+			"existing": existingItemsSize,
+			"excluded": excludedItemsSize,
+		}).Info("Retrieved")
+
+		// loop logic
+		if limitReached {
+			// the limit has been reached for accepted items
+			break
+		}
+
+		if s.Page >= s.TotalPages {
+			break
+		} else {
+			page++
+		}
+	}
+
+	p.log.WithField("accepted_items", mediaItemsSize).Info("Retrieved media items")
+	return mediaItems, nil
+}
+
+func (p *Tmdb) getShows(ctx context.Context, endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	// set request params
+	reqParams, err := p.getRequestParams(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	p.log.Tracef("Request params: %+v", params)
+
+	// parse logic params
+	limit := 0
+	limitReached := false
+
+	if v := getLogicParam(logic, "limit"); v != nil {
+		limit = v.(int)
+	}
+
+	// fetch all page results
+	mediaItems := make(map[string]config.MediaItem)
+	pulledMediaItems := config.NewMediaIndex()
+	mediaItemsSize := 0
+	ignoredItemsSize := 0
+	existingItemsSize := 0
+	excludedItemsSize := 0
+
+	page := 1
+
+	for {
+		// set params
+		reqParams["page"] = page
+
+		// send request
+		resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, reqParams, &p.reqRetry,
+			p.reqRatelimit)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed retrieving shows api response")
+		}
+
+		// validate response
+		if resp.Response().StatusCode != 200 {
+			web.DrainAndClose(resp.Response().Body)
+			return nil, fmt.Errorf("failed retrieving valid shows api response: %s", resp.Response().Status)
+		}
+
+		// decode response
+		var s TmdbShowsResponse
+		if err := resp.ToJSON(&s); err != nil {
+			web.DrainAndClose(resp.Response().Body)
+			return nil, errors.WithMessage(err, "failed decoding shows api response")
+		}
+
+		web.DrainAndClose(resp.Response().Body)
+
+		// process response
+		for _, item := range s.Results {
+			// stop when context is done
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			// have we already pulled this item?
+			tmdbId := strconv.Itoa(item.ID)
+			if pulledMediaItems.ContainsId(config.MediaIdSourceTmdb, tmdbId) {
+				continue
+			}
+
+			// parse item date
+			date, err := time.Parse("2006-01-02", item.FirstAirDate)
+			if err != nil {
+				p.log.WithError(err).Tracef("Failed parsing first air date for item: %+v", item)
+				continue
+			}
+
+			// check the tmdb id before retrieving external ids
+			tmdbItem := config.MediaItem{Provider: "tmdb", Endpoint: endpoint, TmdbId: tmdbId, Title: item.Name,
+				Date: date, Year: date.Year()}
+
+			if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&tmdbItem) {
+				p.log.Debugf("Ignoring excluded: %+v", tmdbItem)
+				excludedItemsSize++
+				continue
+			}
+
+			if p.fnIgnoreExistingMediaItem != nil && p.fnIgnoreExistingMediaItem(&tmdbItem) {
+				p.log.Debugf("Ignoring existing: %+v", tmdbItem)
+				existingItemsSize++
+				continue
+			}
+
+			// retrieve external ids (sonarr requires a tvdb id)
+			externalIds, err := p.getShowExternalIds(ctx, tmdbId)
+			if err != nil {
+				p.log.WithError(err).Tracef("Failed retrieving external ids for item: %+v", item)
+				continue
+			} else if externalIds.TvdbID == 0 {
+				continue
+			}
+
+			// - tvdb check
+			itemId := strconv.Itoa(externalIds.TvdbID)
+			if pulledMediaItems.ContainsId(config.MediaIdSourceTvdb, itemId) {
+				continue
+			}
+
+			// parse item genres
+			var genres []string
+			for _, genreId := range item.GenreIds {
+				if genreName, exists := p.genres[genreId]; exists {
+					genres = append(genres, genreName)
+				}
+			}
+
+			// parse item countries
+			var countries []string
+			for _, country := range item.OriginCountry {
+				countries = append(countries, strings.ToLower(country))
+			}
+
+			// init media item
+			mediaItem := config.MediaItem{
+				Provider:   "tmdb",
+				Endpoint:   endpoint,
+				TvdbId:     itemId,
+				TmdbId:     tmdbId,
+				ImdbId:     externalIds.ImdbID,
+				Title:      item.Name,
+				Summary:    item.Overview,
+				Country:    countries,
+				Network:    "",
+				Date:       date,
+				Year:       date.Year(),
+				Runtime:    0,
+				Genres:     genres,
+				Languages:  []string{item.OriginalLanguage},
+				Rating:     item.VoteAverage,
+				Votes:      item.VoteCount,
+				Popularity: item.Popularity,
+			}
+
+			// has the pvr excluded this item?
+			if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+				excludedItemsSize++
+				continue
+			}
+
+			// does the pvr already have this item?
+			if p.fnIgnoreExistingMediaItem != nil && p.fnIgnoreExistingMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring existing: %+v", mediaItem)
+				existingItemsSize++
+				continue
+			}
+
+			// item passes ignore expressions and is a valid tvdb item?
 			if p.fnAcceptMediaItem != nil && !p.fnAcceptMediaItem(&mediaItem) {
 				p.log.Debugf("Ignoring: %+v", mediaItem)
 				ignoredItemsSize++
 				continue
+			} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, &mediaItem) {
+				p.log.Debugf("Ignoring, invalid TvdbId: %+v", mediaItem)
+				ignoredItemsSize++
+				continue
 			} else {
 				p.log.Debugf("Accepted: %+v", mediaItem)
 			}
 
 			// set media item
 			mediaItems[itemId] = mediaItem
+			pulledMediaItems.Add(mediaItem)
 			mediaItemsSize++
 
 			// stop when limit reached
-			if limit > 0 && mediaItemsSize >= limit {
+			if isLimitReached(logic, limit, mediaItemsSize) {
 				// limit was supplied via cli and we have reached this limit
 				limitReached = true
 				break
@@ -466,6 +1008,7 @@ func (p *Tmdb) getMovies(endpoint string, logic map[string]interface{}, params m
 			"accepted": mediaItemsSize,
 			"ignored":  ignoredItemsSize,
 			"existing": existingItemsSize,
+			"excluded": excludedItemsSize,
 		}).Info("Retrieved")
 
 		// loop logic
@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+/* Test Discover Params */
+
+func TestTmdbDiscoverYearParam(t *testing.T) {
+	p := NewTmdb()
+
+	tests := []struct {
+		year string
+		gte  interface{}
+		lte  interface{}
+	}{
+		{"2019", "2019-01-01", "2019-12-31"},
+		{"2019-2020", "2019-01-01", "2020-12-31"},
+		{"2019-", "2019-01-01", nil},
+		{"-2020", nil, "2020-12-31"},
+	}
+
+	for _, test := range tests {
+		params, err := p.getDiscoverRequestParams(context.Background(), "/discover/movie", map[string]string{"year": test.year})
+		if err != nil {
+			t.Errorf("Failed getting params for year %q: %v", test.year, err)
+			continue
+		}
+
+		if gte := params["primary_release_date.gte"]; gte != test.gte {
+			t.Errorf("Expected year %q to set gte %v, got %v", test.year, test.gte, gte)
+		}
+
+		if lte := params["primary_release_date.lte"]; lte != test.lte {
+			t.Errorf("Expected year %q to set lte %v, got %v", test.year, test.lte, lte)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,7 +10,6 @@ import (
 	"github.com/l3uddz/mediarr/config"
 	"github.com/l3uddz/mediarr/logger"
 	"github.com/l3uddz/mediarr/utils/lists"
-	"github.com/l3uddz/mediarr/utils/media"
 	"github.com/l3uddz/mediarr/utils/web"
 
 	"github.com/imroc/req"
@@ -29,19 +29,23 @@ type Trakt struct {
 	log                       *logrus.Entry
 	cfg                       map[string]string
 	fnIgnoreExistingMediaItem func(*config.MediaItem) bool
+	fnIgnoreExcludedMediaItem func(*config.MediaItem) bool
 	fnAcceptMediaItem         func(*config.MediaItem) bool
+	fnValidateMediaItem       func(context.Context, *config.MediaItem) bool
 
-	apiUrl     string
-	apiHeaders req.Header
-	timeout    int
+	apiUrl        string
+	apiHeaders    req.Header
+	timeout       int
+	authenticated bool
 
 	reqRatelimit *ratelimit.Limiter
 	reqRetry     web.Retry
 
 	genres map[int]string
 
-	supportedShowsSearchTypes  []string
-	supportedMoviesSearchTypes []string
+	supportedShowsSearchTypes   []string
+	supportedMoviesSearchTypes  []string
+	supportedArtistsSearchTypes []string
 }
 
 type TraktMovieIds struct {
@@ -152,6 +156,8 @@ func NewTrakt() *Trakt {
 			SearchTypePerson,
 			SearchTypeQuery,
 			SearchTypeList,
+			SearchTypeWatchlist,
+			SearchTypeRecommended,
 		},
 		supportedMoviesSearchTypes: []string{
 			SearchTypeTrending,
@@ -164,13 +170,16 @@ func NewTrakt() *Trakt {
 			SearchTypePerson,
 			SearchTypeQuery,
 			SearchTypeList,
+			SearchTypeWatchlist,
+			SearchTypeRecommended,
 		},
+		supportedArtistsSearchTypes: []string{},
 	}
 }
 
 /* Interface Implements */
 
-func (p *Trakt) Init(mediaType MediaType, cfg map[string]string) error {
+func (p *Trakt) Init(ctx context.Context, mediaType MediaType, cfg map[string]string) error {
 	// validate we support this media type
 	switch mediaType {
 	case Movie, Show:
@@ -189,6 +198,7 @@ func (p *Trakt) Init(mediaType MediaType, cfg map[string]string) error {
 		return errors.New("provider requires an client_id to be configured")
 	} else {
 		p.apiHeaders["trakt-api-key"] = *v
+		p.apiHeaders["trakt-api-version"] = "2"
 	}
 
 	// set ratelimiter
@@ -197,6 +207,11 @@ func (p *Trakt) Init(mediaType MediaType, cfg map[string]string) error {
 	// set default retry
 	p.reqRetry = providerDefaultRetry
 
+	// load authentication token
+	if err := p.loadToken(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -204,10 +219,18 @@ func (p *Trakt) SetIgnoreExistingMediaItemFn(fn func(*config.MediaItem) bool) {
 	p.fnIgnoreExistingMediaItem = fn
 }
 
+func (p *Trakt) SetIgnoreExcludedMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExcludedMediaItem = fn
+}
+
 func (p *Trakt) SetAcceptMediaItemFn(fn func(*config.MediaItem) bool) {
 	p.fnAcceptMediaItem = fn
 }
 
+func (p *Trakt) SetValidateMediaItemFn(fn func(context.Context, *config.MediaItem) bool) {
+	p.fnValidateMediaItem = fn
+}
+
 func (p *Trakt) GetShowsSearchTypes() []string {
 	return p.supportedShowsSearchTypes
 }
@@ -224,15 +247,23 @@ func (p *Trakt) SupportsMoviesSearchType(searchType string) bool {
 	return lists.StringListContains(p.supportedMoviesSearchTypes, searchType, false)
 }
 
-func (p *Trakt) GetShows(searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *Trakt) GetArtistsSearchTypes() []string {
+	return p.supportedArtistsSearchTypes
+}
+
+func (p *Trakt) SupportsArtistsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedArtistsSearchTypes, searchType, false)
+}
+
+func (p *Trakt) GetShows(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 
 	switch searchType {
 	case SearchTypePopular:
-		return p.getShows("/shows/popular", logic, params)
+		return p.getShows(ctx, "/shows/popular", logic, params)
 	case SearchTypeTrending:
-		return p.getShows("/shows/trending", logic, params)
+		return p.getShows(ctx, "/shows/trending", logic, params)
 	case SearchTypeAnticipated:
-		return p.getShows("/shows/anticipated", logic, params)
+		return p.getShows(ctx, "/shows/anticipated", logic, params)
 	case SearchTypeWatched, SearchTypePlayed, SearchTypeCollected:
 		// get period from query param (default to weekly if not provided)
 		period, err := p.getPeriodFromQueryStr(params)
@@ -240,33 +271,48 @@ func (p *Trakt) GetShows(searchType string, logic map[string]interface{}, params
 			return nil, err
 		}
 
-		return p.getShows(fmt.Sprintf("/shows/%s/%s", searchType, period), logic, params)
+		return p.getShows(ctx, fmt.Sprintf("/shows/%s/%s", searchType, period), logic, params)
 	case SearchTypePerson:
 		queryStr, ok := params["query"]
 		if !ok || queryStr == "" {
 			return nil, errors.New("person search must have a --query string, e.g. bryan-cranston")
 		}
 
-		return p.getShows(fmt.Sprintf("/people/%s/shows", queryStr), logic, params)
+		return p.getShows(ctx, fmt.Sprintf("/people/%s/shows", queryStr), logic, params)
 	case SearchTypeQuery:
 		queryStr, ok := params["query"]
 		if !ok || queryStr == "" {
 			return nil, errors.New("query search must have a --query string, e.g. imdb_ratings=5.0-10")
 		}
 
-		return p.getShows(fmt.Sprintf("/search/show?query=&%s", queryStr), logic, params)
+		return p.getShows(ctx, fmt.Sprintf("/search/show?query=&%s", queryStr), logic, params)
 
 	case SearchTypeList:
-		listUser, ok := params["listuser"]
-		if !ok || listUser == "" {
-			return nil, errors.New("list search must have a --listuser string, e.g. enormoz")
+		listUser, err := p.getListUser(params)
+		if err != nil {
+			return nil, err
 		}
 		listName, ok := params["listname"]
 		if !ok || listName == "" {
 			return nil, errors.New("list search must have a --listname string, e.g. netflix-movies")
 		}
 
-		return p.getShows(fmt.Sprintf("/users/%s/lists/%s/items/shows", listUser, listName), logic, params)
+		return p.getShows(ctx, fmt.Sprintf("/users/%s/lists/%s/items/shows", listUser, listName), logic, params)
+
+	case SearchTypeWatchlist:
+		listUser, err := p.getListUser(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getShows(ctx, fmt.Sprintf("/users/%s/watchlist/shows", listUser), logic, params)
+
+	case SearchTypeRecommended:
+		if !p.authenticated {
+			return nil, errors.New("recommended search requires authentication, run: mediarr auth trakt")
+		}
+
+		return p.getShows(ctx, "/recommendations/shows", logic, params)
 
 	default:
 		break
@@ -275,50 +321,65 @@ func (p *Trakt) GetShows(searchType string, logic map[string]interface{}, params
 	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
 }
 
-func (p *Trakt) GetMovies(searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *Trakt) GetMovies(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 
 	switch searchType {
 	case SearchTypePopular:
-		return p.getMovies("/movies/popular", logic, params)
+		return p.getMovies(ctx, "/movies/popular", logic, params)
 	case SearchTypeAnticipated:
-		return p.getMovies("/movies/anticipated", logic, params)
+		return p.getMovies(ctx, "/movies/anticipated", logic, params)
 	case SearchTypeTrending:
-		return p.getMovies("/movies/trending", logic, params)
+		return p.getMovies(ctx, "/movies/trending", logic, params)
 	case SearchTypeNow:
-		return p.getMovies("/movies/boxoffice", logic, params)
+		return p.getMovies(ctx, "/movies/boxoffice", logic, params)
 	case SearchTypeWatched, SearchTypePlayed, SearchTypeCollected:
 		// get period from query param (default to weekly if not provided)
 		period, err := p.getPeriodFromQueryStr(params)
 		if err != nil {
 			return nil, err
 		}
-		return p.getMovies(fmt.Sprintf("/movies/%s/%s", searchType, period), logic, params)
+		return p.getMovies(ctx, fmt.Sprintf("/movies/%s/%s", searchType, period), logic, params)
 	case SearchTypePerson:
 		queryStr, ok := params["query"]
 		if !ok || queryStr == "" {
 			return nil, errors.New("person search must have a --query string, e.g. bryan-cranston")
 		}
 
-		return p.getMovies(fmt.Sprintf("/people/%s/movies", queryStr), logic, params)
+		return p.getMovies(ctx, fmt.Sprintf("/people/%s/movies", queryStr), logic, params)
 	case SearchTypeQuery:
 		queryStr, ok := params["query"]
 		if !ok || queryStr == "" {
 			return nil, errors.New("query search must have a --query string, e.g. imdb_ratings=5.0-10")
 		}
 
-		return p.getMovies(fmt.Sprintf("/search/movie?query=&%s", queryStr), logic, params)
+		return p.getMovies(ctx, fmt.Sprintf("/search/movie?query=&%s", queryStr), logic, params)
 
 	case SearchTypeList:
-		listUser, ok := params["listuser"]
-		if !ok || listUser == "" {
-			return nil, errors.New("list search must have a --listuser string, e.g. enormoz")
+		listUser, err := p.getListUser(params)
+		if err != nil {
+			return nil, err
 		}
 		listName, ok := params["listname"]
 		if !ok || listName == "" {
 			return nil, errors.New("list search must have a --listname string, e.g. netflix-movies")
 		}
 
-		return p.getMovies(fmt.Sprintf("/users/%s/lists/%s/items/movies", listUser, listName), logic, params)
+		return p.getMovies(ctx, fmt.Sprintf("/users/%s/lists/%s/items/movies", listUser, listName), logic, params)
+
+	case SearchTypeWatchlist:
+		listUser, err := p.getListUser(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getMovies(ctx, fmt.Sprintf("/users/%s/watchlist/movies", listUser), logic, params)
+
+	case SearchTypeRecommended:
+		if !p.authenticated {
+			return nil, errors.New("recommended search requires authentication, run: mediarr auth trakt")
+		}
+
+		return p.getMovies(ctx, "/recommendations/movies", logic, params)
 
 	default:
 		break
@@ -327,6 +388,10 @@ func (p *Trakt) GetMovies(searchType string, logic map[string]interface{}, param
 	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
 }
 
+func (p *Trakt) GetArtists(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	return nil, errors.New("unsupported media type")
+}
+
 /* Private - Sub-Implements */
 
 func (p *Trakt) getPeriodFromQueryStr(params map[string]string) (string, error) {
@@ -344,6 +409,20 @@ func (p *Trakt) getPeriodFromQueryStr(params map[string]string) (string, error)
 	return "weekly", nil
 }
 
+func (p *Trakt) getListUser(params map[string]string) (string, error) {
+	listUser, ok := params["listuser"]
+
+	switch {
+	case ok && listUser != "":
+		return listUser, nil
+	case p.authenticated:
+		// default to the authenticated user
+		return "me", nil
+	default:
+		return "", errors.New("list search must have a --listuser string, e.g. enormoz (or authenticate with: mediarr auth trakt)")
+	}
+}
+
 func (p *Trakt) getRequestParams(params map[string]string) req.Param {
 	// set request params
 	reqParams := req.Param{
@@ -471,7 +550,7 @@ func (p *Trakt) translateShow(response TraktShowsResponse) *TraktShow {
 	}
 }
 
-func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *Trakt) getMovies(ctx context.Context, endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 	// set request params
 	reqParams := p.getRequestParams(params)
 
@@ -487,9 +566,11 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 
 	// fetch all page results
 	mediaItems := make(map[string]config.MediaItem)
+	pulledMediaItems := config.NewMediaIndex()
 	mediaItemsSize := 0
 	ignoredItemsSize := 0
 	existingItemsSize := 0
+	excludedItemsSize := 0
 
 	page := 1
 
@@ -498,7 +579,7 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 		reqParams["page"] = page
 
 		// send request
-		resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, p.apiHeaders, reqParams,
+		resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, p.apiHeaders, reqParams,
 			&p.reqRetry, p.reqRatelimit)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed retrieving movies api response")
@@ -534,6 +615,11 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 
 		// process response
 		for _, item := range s {
+			// stop when context is done
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
 			// set movie item
 			var movieItem *TraktMovie = p.translateMovie(item)
 			if movieItem == nil {
@@ -561,21 +647,13 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 			}
 
 			// have we already pulled this item?
-			// -- tmdb check
 			itemId := strconv.Itoa(movieItem.Ids.Tmdb)
-			if _, exists := mediaItems[itemId]; exists {
+			if pulledMediaItems.ContainsId(config.MediaIdSourceTmdb, itemId) {
 				continue
-			} else if _, exists := mediaItems[itemId]; exists {
+			} else if pulledMediaItems.ContainsId(config.MediaIdSourceImdb, movieItem.Ids.Imdb) {
 				continue
 			}
 
-			// -- imdb check
-			if movieItem.Ids.Imdb != "" {
-				if _, exists := mediaItems[itemId]; exists {
-					continue
-				}
-			}
-
 			// parse item date
 			date, err := time.Parse("2006-01-02", movieItem.Released)
 			if err != nil {
@@ -585,23 +663,33 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 
 			// init media item
 			mediaItem := config.MediaItem{
-				Provider:  "trakt",
-				Endpoint:  endpoint,
-				TvdbId:    "",
-				TmdbId:    itemId,
-				ImdbId:    movieItem.Ids.Imdb,
-				Slug:      movieItem.Ids.Slug,
-				Title:     movieItem.Title,
-				Summary:   movieItem.Overview,
-				Country:   []string{movieItem.Country},
-				Network:   "",
-				Date:      date,
-				Year:      date.Year(),
-				Runtime:   movieItem.Runtime,
-				Status:    movieItem.Status,
-				Genres:    movieItem.Genres,
-				Languages: []string{movieItem.Language},
-				Character: movieItem.Character,
+				Provider:      "trakt",
+				Endpoint:      endpoint,
+				TvdbId:        "",
+				TmdbId:        itemId,
+				ImdbId:        movieItem.Ids.Imdb,
+				Slug:          movieItem.Ids.Slug,
+				Title:         movieItem.Title,
+				Summary:       movieItem.Overview,
+				Country:       []string{movieItem.Country},
+				Network:       "",
+				Date:          date,
+				Year:          date.Year(),
+				Runtime:       movieItem.Runtime,
+				Status:        movieItem.Status,
+				Genres:        movieItem.Genres,
+				Languages:     []string{movieItem.Language},
+				Character:     movieItem.Character,
+				Rating:        movieItem.Rating,
+				Votes:         movieItem.Votes,
+				Certification: movieItem.Certification,
+			}
+
+			// has the pvr excluded this item?
+			if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+				excludedItemsSize++
+				continue
 			}
 
 			// does the pvr already have this item?
@@ -616,7 +704,7 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 				p.log.Debugf("Ignoring: %+v", mediaItem)
 				ignoredItemsSize++
 				continue
-			} else if !media.ValidateTmdbId("movie", itemId) {
+			} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, &mediaItem) {
 				p.log.Debugf("Ignoring, invalid TmdbId: %+v", mediaItem)
 				ignoredItemsSize++
 				continue
@@ -626,10 +714,11 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 
 			// set media item
 			mediaItems[itemId] = mediaItem
+			pulledMediaItems.Add(mediaItem)
 			mediaItemsSize++
 
 			// stop when limit reached
-			if limit > 0 && mediaItemsSize >= limit {
+			if isLimitReached(logic, limit, mediaItemsSize) {
 				// limit was supplied via cli and we have reached this limit
 				limitReached = true
 				break
@@ -649,6 +738,7 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 			"accepted": mediaItemsSize,
 			"ignored":  ignoredItemsSize,
 			"existing": existingItemsSize,
+			"excluded": excludedItemsSize,
 		}).Info("Retrieved")
 
 		// loop logic
@@ -668,7 +758,7 @@ func (p *Trakt) getMovies(endpoint string, logic map[string]interface{}, params
 	return mediaItems, nil
 }
 
-func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *Trakt) getShows(ctx context.Context, endpoint string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 	// set request params
 	reqParams := p.getRequestParams(params)
 
@@ -684,9 +774,11 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 
 	// fetch all page results
 	mediaItems := make(map[string]config.MediaItem)
+	pulledMediaItems := config.NewMediaIndex()
 	mediaItemsSize := 0
 	ignoredItemsSize := 0
 	existingItemsSize := 0
+	excludedItemsSize := 0
 
 	page := 1
 
@@ -695,7 +787,7 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 		reqParams["page"] = page
 
 		// send request
-		resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, p.apiHeaders, reqParams,
+		resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, endpoint), p.timeout, p.apiHeaders, reqParams,
 			&p.reqRetry, p.reqRatelimit)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed retrieving shows api response")
@@ -731,6 +823,11 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 
 		// process response
 		for _, item := range s {
+			// stop when context is done
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
 			// set movie item
 			var showItem *TraktShow = p.translateShow(item)
 			if showItem == nil {
@@ -757,33 +854,43 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 			}
 
 			// have we already pulled this item?
-			// - tvdb check
 			itemId := strconv.Itoa(showItem.Ids.Tvdb)
-			if _, exists := mediaItems[itemId]; exists {
+			if pulledMediaItems.ContainsId(config.MediaIdSourceTvdb, itemId) {
 				continue
-			} else if _, exists := mediaItems[itemId]; exists {
+			} else if pulledMediaItems.ContainsId(config.MediaIdSourceImdb, showItem.Ids.Imdb) {
 				continue
 			}
 
 			// init media item
 			mediaItem := config.MediaItem{
-				Provider:  "trakt",
-				Endpoint:  endpoint,
-				TvdbId:    itemId,
-				TmdbId:    strconv.Itoa(showItem.Ids.Tmdb),
-				ImdbId:    showItem.Ids.Imdb,
-				Slug:      showItem.Ids.Slug,
-				Title:     showItem.Title,
-				Summary:   showItem.Overview,
-				Country:   []string{showItem.Country},
-				Network:   showItem.Network,
-				Date:      showItem.FirstAired,
-				Year:      showItem.FirstAired.Year(),
-				Runtime:   showItem.Runtime,
-				Status:    showItem.Status,
-				Genres:    showItem.Genres,
-				Languages: []string{showItem.Language},
-				Character: showItem.Character,
+				Provider:      "trakt",
+				Endpoint:      endpoint,
+				TvdbId:        itemId,
+				TmdbId:        strconv.Itoa(showItem.Ids.Tmdb),
+				ImdbId:        showItem.Ids.Imdb,
+				Slug:          showItem.Ids.Slug,
+				Title:         showItem.Title,
+				Summary:       showItem.Overview,
+				Country:       []string{showItem.Country},
+				Network:       showItem.Network,
+				Date:          showItem.FirstAired,
+				Year:          showItem.FirstAired.Year(),
+				Runtime:       showItem.Runtime,
+				Status:        showItem.Status,
+				Genres:        showItem.Genres,
+				Languages:     []string{showItem.Language},
+				Character:     showItem.Character,
+				Rating:        showItem.Rating,
+				Votes:         showItem.Votes,
+				Certification: showItem.Certification,
+				Episodes:      showItem.AiredEpisodes,
+			}
+
+			// has the pvr excluded this item?
+			if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&mediaItem) {
+				p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+				excludedItemsSize++
+				continue
 			}
 
 			// does the pvr already have this item?
@@ -798,7 +905,7 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 				p.log.Debugf("Ignoring: %+v", mediaItem)
 				ignoredItemsSize++
 				continue
-			} else if !media.ValidateTvdbId(itemId) {
+			} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, &mediaItem) {
 				p.log.Debugf("Ignoring, invalid TvdbId: %+v", mediaItem)
 				ignoredItemsSize++
 				continue
@@ -808,10 +915,11 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 
 			// set media item
 			mediaItems[itemId] = mediaItem
+			pulledMediaItems.Add(mediaItem)
 			mediaItemsSize++
 
 			// stop when limit reached
-			if limit > 0 && mediaItemsSize >= limit {
+			if isLimitReached(logic, limit, mediaItemsSize) {
 				// limit was supplied via cli and we have reached this limit
 				limitReached = true
 				break
@@ -831,6 +939,7 @@ func (p *Trakt) getShows(endpoint string, logic map[string]interface{}, params m
 			"accepted": mediaItemsSize,
 			"ignored":  ignoredItemsSize,
 			"existing": existingItemsSize,
+			"excluded": excludedItemsSize,
 		}).Info("Retrieved")
 
 		// loop logic
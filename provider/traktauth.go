@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/database"
+	"github.com/l3uddz/mediarr/utils/web"
+
+	"github.com/imroc/req"
+	"github.com/pkg/errors"
+)
+
+/* Const */
+
+const (
+	traktRedirectUri        = "urn:ietf:wg:oauth:2.0:oob"
+	traktTokenRefreshWindow = 24 * time.Hour
+)
+
+/* Struct */
+
+type TraktDeviceCodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationUrl string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type TraktTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+/* Public */
+
+func (p *Trakt) Authorize(ctx context.Context, cfg map[string]string) error {
+	// validate client credentials set
+	clientId, clientSecret, err := p.getClientCredentials(cfg)
+	if err != nil {
+		return err
+	}
+
+	// set ratelimiter
+	p.reqRatelimit = web.GetRateLimiter("trakt", TraktRateLimit)
+
+	// request device code
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "oauth", "device", "code"), p.timeout,
+		p.getAuthHeaders(clientId), req.BodyJSON(map[string]string{
+			"client_id": clientId,
+		}), p.reqRatelimit)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving device code api response")
+	}
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		web.DrainAndClose(resp.Response().Body)
+		return fmt.Errorf("failed retrieving valid device code api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s TraktDeviceCodeResponse
+	if err := resp.ToJSON(&s); err != nil {
+		web.DrainAndClose(resp.Response().Body)
+		return errors.WithMessage(err, "failed decoding device code api response")
+	}
+
+	web.DrainAndClose(resp.Response().Body)
+
+	p.log.Infof("Visit %s and enter the code: %s", s.VerificationUrl, s.UserCode)
+
+	// poll for token
+	interval := time.Duration(s.Interval) * time.Second
+	expires := time.Now().Add(time.Duration(s.ExpiresIn) * time.Second)
+
+	for time.Now().Before(expires) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		// send request
+		resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "oauth", "device", "token"), p.timeout,
+			p.getAuthHeaders(clientId), req.BodyJSON(map[string]string{
+				"code":          s.DeviceCode,
+				"client_id":     clientId,
+				"client_secret": clientSecret,
+			}), p.reqRatelimit)
+		if err != nil {
+			return errors.WithMessage(err, "failed retrieving device token api response")
+		}
+
+		// validate response
+		switch resp.Response().StatusCode {
+		case 200:
+			break
+		case 400:
+			// authorization pending
+			web.DrainAndClose(resp.Response().Body)
+			p.log.Trace("Waiting for authorization...")
+			continue
+		case 429:
+			// polling too quickly
+			web.DrainAndClose(resp.Response().Body)
+			interval += time.Second
+			continue
+		case 418:
+			web.DrainAndClose(resp.Response().Body)
+			return errors.New("authorization was denied")
+		default:
+			web.DrainAndClose(resp.Response().Body)
+			return fmt.Errorf("failed retrieving valid device token api response: %s", resp.Response().Status)
+		}
+
+		// decode response
+		var t TraktTokenResponse
+		if err := resp.ToJSON(&t); err != nil {
+			web.DrainAndClose(resp.Response().Body)
+			return errors.WithMessage(err, "failed decoding device token api response")
+		}
+
+		web.DrainAndClose(resp.Response().Body)
+
+		// store token
+		if err := p.storeToken(&t); err != nil {
+			return err
+		}
+
+		p.log.Info("Successfully authorized")
+		return nil
+	}
+
+	return errors.New("device code expired before authorization was completed")
+}
+
+/* Private */
+
+func (p *Trakt) getClientCredentials(cfg map[string]string) (string, string, error) {
+	if cfg == nil {
+		return "", "", errors.New("provider has no configuration data set")
+	}
+
+	clientId, err := config.GetProviderSetting(cfg, "client_id")
+	if err != nil {
+		return "", "", errors.New("provider requires an client_id to be configured")
+	}
+
+	clientSecret, err := config.GetProviderSetting(cfg, "client_secret")
+	if err != nil {
+		return "", "", errors.New("provider requires an client_secret to be configured for authentication")
+	}
+
+	return *clientId, *clientSecret, nil
+}
+
+func (p *Trakt) getAuthHeaders(clientId string) req.Header {
+	return req.Header{
+		"trakt-api-key":     clientId,
+		"trakt-api-version": "2",
+	}
+}
+
+func (p *Trakt) storeToken(t *TraktTokenResponse) error {
+	// determine token expiry
+	created := time.Now()
+	if t.CreatedAt > 0 {
+		created = time.Unix(t.CreatedAt, 0)
+	}
+
+	expires := created.Add(time.Duration(t.ExpiresIn) * time.Second)
+
+	// add token to database
+	if err := database.SetProviderToken("trakt", t.AccessToken, t.RefreshToken, expires); err != nil {
+		return errors.WithMessage(err, "failed storing token")
+	}
+
+	return nil
+}
+
+func (p *Trakt) loadToken(ctx context.Context) error {
+	// retrieve token from database
+	token, err := database.GetProviderToken("trakt")
+	if err != nil {
+		p.log.Trace("No authentication token found")
+		return nil
+	}
+
+	// refresh token when it is about to expire
+	if time.Now().UTC().Add(traktTokenRefreshWindow).After(token.Expires) {
+		clientId, clientSecret, err := p.getClientCredentials(p.cfg)
+		if err != nil {
+			return err
+		}
+
+		if err := p.refreshToken(ctx, clientId, clientSecret, token.RefreshToken); err != nil {
+			if token.Expires.Before(time.Now().UTC()) {
+				return errors.WithMessage(err, "failed refreshing expired token, run: mediarr auth trakt")
+			}
+
+			p.log.WithError(err).Warn("Failed refreshing token")
+		} else if token, err = database.GetProviderToken("trakt"); err != nil {
+			return errors.WithMessage(err, "failed retrieving refreshed token")
+		}
+	}
+
+	// set authorization header
+	p.apiHeaders["Authorization"] = "Bearer " + token.AccessToken
+	p.authenticated = true
+
+	p.log.WithField("expires", token.Expires.Format(time.RFC3339)).Debug("Loaded authentication token")
+	return nil
+}
+
+func (p *Trakt) refreshToken(ctx context.Context, clientId string, clientSecret string, refreshToken string) error {
+	// send request
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "oauth", "token"), p.timeout,
+		p.getAuthHeaders(clientId), req.BodyJSON(map[string]string{
+			"refresh_token": refreshToken,
+			"client_id":     clientId,
+			"client_secret": clientSecret,
+			"redirect_uri":  traktRedirectUri,
+			"grant_type":    "refresh_token",
+		}), &p.reqRetry, p.reqRatelimit)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving refresh token api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return fmt.Errorf("failed retrieving valid refresh token api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var t TraktTokenResponse
+	if err := resp.ToJSON(&t); err != nil {
+		return errors.WithMessage(err, "failed decoding refresh token api response")
+	}
+
+	// store token
+	if err := p.storeToken(&t); err != nil {
+		return err
+	}
+
+	p.log.Info("Refreshed authentication token")
+	return nil
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -8,7 +9,6 @@ import (
 	"github.com/l3uddz/mediarr/config"
 	"github.com/l3uddz/mediarr/logger"
 	"github.com/l3uddz/mediarr/utils/lists"
-	"github.com/l3uddz/mediarr/utils/media"
 	"github.com/l3uddz/mediarr/utils/web"
 
 	"github.com/pkg/errors"
@@ -28,7 +28,9 @@ type TvMaze struct {
 	log                       *logrus.Entry
 	cfg                       map[string]string
 	fnIgnoreExistingMediaItem func(*config.MediaItem) bool
+	fnIgnoreExcludedMediaItem func(*config.MediaItem) bool
 	fnAcceptMediaItem         func(*config.MediaItem) bool
+	fnValidateMediaItem       func(context.Context, *config.MediaItem) bool
 
 	apiUrl  string
 	apiKey  string
@@ -37,8 +39,9 @@ type TvMaze struct {
 	reqRatelimit *ratelimit.Limiter
 	reqRetry     web.Retry
 
-	supportedShowsSearchTypes  []string
-	supportedMoviesSearchTypes []string
+	supportedShowsSearchTypes   []string
+	supportedMoviesSearchTypes  []string
+	supportedArtistsSearchTypes []string
 }
 
 type TvMazeScheduleItem struct {
@@ -124,13 +127,14 @@ func NewTvMaze() *TvMaze {
 		supportedShowsSearchTypes: []string{
 			SearchTypeSchedule,
 		},
-		supportedMoviesSearchTypes: []string{},
+		supportedMoviesSearchTypes:  []string{},
+		supportedArtistsSearchTypes: []string{},
 	}
 }
 
 /* Interface Implements */
 
-func (p *TvMaze) Init(mediaType MediaType, cfg map[string]string) error {
+func (p *TvMaze) Init(ctx context.Context, mediaType MediaType, cfg map[string]string) error {
 	// validate we support this media type
 	switch mediaType {
 	case Show:
@@ -155,10 +159,18 @@ func (p *TvMaze) SetIgnoreExistingMediaItemFn(fn func(*config.MediaItem) bool) {
 	p.fnIgnoreExistingMediaItem = fn
 }
 
+func (p *TvMaze) SetIgnoreExcludedMediaItemFn(fn func(*config.MediaItem) bool) {
+	p.fnIgnoreExcludedMediaItem = fn
+}
+
 func (p *TvMaze) SetAcceptMediaItemFn(fn func(*config.MediaItem) bool) {
 	p.fnAcceptMediaItem = fn
 }
 
+func (p *TvMaze) SetValidateMediaItemFn(fn func(context.Context, *config.MediaItem) bool) {
+	p.fnValidateMediaItem = fn
+}
+
 func (p *TvMaze) GetShowsSearchTypes() []string {
 	return p.supportedShowsSearchTypes
 }
@@ -175,11 +187,19 @@ func (p *TvMaze) SupportsMoviesSearchType(searchType string) bool {
 	return lists.StringListContains(p.supportedMoviesSearchTypes, searchType, false)
 }
 
-func (p *TvMaze) GetShows(searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *TvMaze) GetArtistsSearchTypes() []string {
+	return p.supportedArtistsSearchTypes
+}
+
+func (p *TvMaze) SupportsArtistsSearchType(searchType string) bool {
+	return lists.StringListContains(p.supportedArtistsSearchTypes, searchType, false)
+}
+
+func (p *TvMaze) GetShows(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 
 	switch searchType {
 	case SearchTypeSchedule:
-		return p.getScheduleShows(logic, params)
+		return p.getScheduleShows(ctx, logic, params)
 	default:
 		break
 	}
@@ -187,15 +207,19 @@ func (p *TvMaze) GetShows(searchType string, logic map[string]interface{}, param
 	return nil, fmt.Errorf("unsupported search_type: %q", searchType)
 }
 
-func (p *TvMaze) GetMovies(searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+func (p *TvMaze) GetMovies(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
+	return nil, errors.New("unsupported media type")
+}
+
+func (p *TvMaze) GetArtists(ctx context.Context, searchType string, logic map[string]interface{}, params map[string]string) (map[string]config.MediaItem, error) {
 	return nil, errors.New("unsupported media type")
 }
 
 /* Private - Sub-Implements */
 
-func (p *TvMaze) getScheduleShows(logic map[string]interface{}, _ map[string]string) (map[string]config.MediaItem, error) {
+func (p *TvMaze) getScheduleShows(ctx context.Context, logic map[string]interface{}, _ map[string]string) (map[string]config.MediaItem, error) {
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "schedule", "full"), p.timeout, &p.reqRetry,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "schedule", "full"), p.timeout, &p.reqRetry,
 		p.reqRatelimit)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed retrieving full schedule api response")
@@ -225,8 +249,14 @@ func (p *TvMaze) getScheduleShows(logic map[string]interface{}, _ map[string]str
 	mediaItemsSize := 0
 	ignoredItemsSize := 0
 	existingItemsSize := 0
+	excludedItemsSize := 0
 
 	for _, item := range s {
+		// stop when context is done
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// skip invalid items
 		if item.Embedded.Show.Externals.Thetvdb < 1 {
 			continue
@@ -253,18 +283,30 @@ func (p *TvMaze) getScheduleShows(logic map[string]interface{}, _ map[string]str
 
 		// init media item
 		mediaItem := config.MediaItem{
-			Provider:  "tvmaze",
-			Endpoint:  "/schedule/full",
-			TvdbId:    itemId,
-			ImdbId:    item.Embedded.Show.Externals.Imdb,
-			Title:     item.Embedded.Show.Name,
-			Summary:   item.Embedded.Show.Summary,
-			Network:   item.Embedded.Show.Network.Name,
-			Date:      date,
-			Year:      date.Year(),
-			Runtime:   item.Runtime,
-			Languages: []string{item.Embedded.Show.Language},
-			Genres:    []string{item.Embedded.Show.Type},
+			Provider:   "tvmaze",
+			Endpoint:   "/schedule/full",
+			TvdbId:     itemId,
+			ImdbId:     item.Embedded.Show.Externals.Imdb,
+			Title:      item.Embedded.Show.Name,
+			Summary:    item.Embedded.Show.Summary,
+			Network:    item.Embedded.Show.Network.Name,
+			Date:       date,
+			Year:       date.Year(),
+			Runtime:    item.Runtime,
+			Languages:  []string{item.Embedded.Show.Language},
+			Genres:     []string{item.Embedded.Show.Type},
+			Popularity: float64(item.Embedded.Show.Weight),
+		}
+
+		if rating, ok := item.Embedded.Show.Rating.Average.(float64); ok {
+			mediaItem.Rating = rating
+		}
+
+		// has the pvr excluded this item?
+		if p.fnIgnoreExcludedMediaItem != nil && p.fnIgnoreExcludedMediaItem(&mediaItem) {
+			p.log.Debugf("Ignoring excluded: %+v", mediaItem)
+			excludedItemsSize++
+			continue
 		}
 
 		// does the pvr already have this item?
@@ -279,7 +321,7 @@ func (p *TvMaze) getScheduleShows(logic map[string]interface{}, _ map[string]str
 			p.log.Debugf("Ignoring: %+v", mediaItem)
 			ignoredItemsSize++
 			continue
-		} else if !media.ValidateTvdbId(itemId) {
+		} else if p.fnValidateMediaItem != nil && !p.fnValidateMediaItem(ctx, &mediaItem) {
 			p.log.Debugf("Ignoring, bad TvdbId: %+v", mediaItem)
 			ignoredItemsSize++
 			continue
@@ -292,7 +334,7 @@ func (p *TvMaze) getScheduleShows(logic map[string]interface{}, _ map[string]str
 		mediaItemsSize++
 
 		// stop when limit reached
-		if limit > 0 && mediaItemsSize >= limit {
+		if isLimitReached(logic, limit, mediaItemsSize) {
 			// limit was supplied via cli and we have reached this limit
 			break
 		}
@@ -302,6 +344,7 @@ func (p *TvMaze) getScheduleShows(logic map[string]interface{}, _ map[string]str
 		"accepted": mediaItemsSize,
 		"ignored":  ignoredItemsSize,
 		"existing": existingItemsSize,
+		"excluded": excludedItemsSize,
 	}).Info("Retrieved media items")
 	return mediaItems, nil
 }
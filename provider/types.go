@@ -5,6 +5,7 @@ type MediaType int
 const (
 	Show MediaType = iota + 1
 	Movie
+	Music
 )
 
 const (
@@ -20,4 +21,12 @@ const (
 	SearchTypePerson      string = "person"
 	SearchTypeQuery       string = "query"
 	SearchTypeList        string = "list"
+	SearchTypeTopRated    string = "top_rated"
+	SearchTypeOnTheAir    string = "on_the_air"
+	SearchTypeAiringToday string = "airing_today"
+	SearchTypeDiscover    string = "discover"
+	SearchTypeWatchlist   string = "watchlist"
+	SearchTypeRecommended string = "recommended"
+	SearchTypeBest        string = "best"
+	SearchTypeAnimeAiring string = "anime_airing"
 )
@@ -1,5 +1,7 @@
 package provider
 
+import "strings"
+
 func getLogicParam(logic map[string]interface{}, key string) interface{} {
 	if v, exists := logic[key]; exists {
 		return v
@@ -7,3 +9,26 @@ func getLogicParam(logic map[string]interface{}, key string) interface{} {
 
 	return nil
 }
+
+func isLimitReached(logic map[string]interface{}, limit int, size int) bool {
+	if limit > 0 && size >= limit {
+		return true
+	}
+
+	// the caller can decide when enough items were accepted, e.g. when every pvr is full
+	if v := getLogicParam(logic, "limit_reached"); v != nil {
+		return v.(func() bool)()
+	}
+
+	return false
+}
+
+func getRangeParam(value string) (string, string, bool) {
+	// split ranges, e.g. 2019-2020 or 7.5-10, either bound can be left open, e.g. 2019- or -2020
+	value = strings.TrimSpace(value)
+	if idx := strings.Index(value, "-"); idx >= 0 {
+		return strings.TrimSpace(value[:idx]), strings.TrimSpace(value[idx+1:]), true
+	}
+
+	return value, "", false
+}
@@ -0,0 +1,30 @@
+package provider
+
+import "testing"
+
+/* Test Range Param */
+
+func TestGetRangeParam(t *testing.T) {
+	tests := []struct {
+		value   string
+		from    string
+		to      string
+		isRange bool
+	}{
+		{"2019", "2019", "", false},
+		{"2019-2020", "2019", "2020", true},
+		{"2019-", "2019", "", true},
+		{"-2020", "", "2020", true},
+		{" 2019 - 2020 ", "2019", "2020", true},
+		{"7.5-10", "7.5", "10", true},
+		{"7.5", "7.5", "", false},
+	}
+
+	for _, test := range tests {
+		from, to, isRange := getRangeParam(test.value)
+		if from != test.from || to != test.to || isRange != test.isRange {
+			t.Errorf("Expected range %q to be (%q, %q, %v), got (%q, %q, %v)", test.value, test.from, test.to,
+				test.isRange, from, to, isRange)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package pvr
+
+import (
+	"github.com/l3uddz/mediarr/config"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/pkg/errors"
+)
+
+/* Structs */
+
+type Filters struct {
+	cfg         *config.PvrFilters
+	acceptsExpr []*vm.Program
+	ignoresExpr []*vm.Program
+}
+
+/* Initializer */
+
+func NewFilters(cfg *config.PvrFilters) (*Filters, error) {
+	var err error
+	f := &Filters{cfg: cfg}
+
+	// compile accepts
+	if f.acceptsExpr, err = compileExpressions("accept", cfg.Accepts); err != nil {
+		return nil, err
+	}
+
+	// compile ignores
+	ignores := make([]string, 0, len(cfg.Ignores))
+	for _, ignore := range cfg.Ignores {
+		ignores = append(ignores, ignore.When)
+	}
+
+	if f.ignoresExpr, err = compileExpressions("ignore", ignores); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+/* Public */
+
+func (f *Filters) ExplainIgnore(mediaItem *config.MediaItem) (*FilterMatch, error) {
+	exprItem := config.GetExprEnv(mediaItem)
+
+	// the item must match every accept expression
+	for pos, expression := range f.acceptsExpr {
+		accept, err := runExpression("accept", expression, exprItem)
+		if err != nil {
+			return nil, err
+		}
+
+		if !accept {
+			return &FilterMatch{
+				Type:       FilterTypeAccept,
+				Index:      pos,
+				Expression: f.cfg.Accepts[pos],
+			}, nil
+		}
+	}
+
+	// the item must not match any ignore expression
+	for pos, expression := range f.ignoresExpr {
+		ignore, err := runExpression("ignore", expression, exprItem)
+		if err != nil {
+			return nil, err
+		}
+
+		if ignore {
+			return &FilterMatch{
+				Type:       FilterTypeIgnore,
+				Index:      pos,
+				Expression: f.cfg.Ignores[pos].When,
+				Exclude:    f.cfg.Ignores[pos].Exclude,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+/* Private */
+
+func compileExpressions(exprType string, expressions []string) ([]*vm.Program, error) {
+	exprEnv := &config.ExprEnv{}
+	programs := make([]*vm.Program, 0)
+
+	for _, expression := range expressions {
+		program, err := expr.Compile(expression, expr.Env(exprEnv), expr.AsBool())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed compiling %s expression for: %q", exprType, expression)
+		}
+
+		programs = append(programs, program)
+	}
+
+	return programs, nil
+}
+
+func runExpression(exprType string, program *vm.Program, exprItem *config.ExprEnv) (bool, error) {
+	result, err := expr.Run(program, exprItem)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed checking %s expression", exprType)
+	}
+
+	expResult, ok := result.(bool)
+	if !ok {
+		return false, errors.Errorf("failed type asserting %s expression result", exprType)
+	}
+
+	return expResult, nil
+}
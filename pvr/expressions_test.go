@@ -0,0 +1,58 @@
+package pvr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/l3uddz/mediarr/config"
+)
+
+/* Test Explain Ignore */
+
+func TestExplainIgnore(t *testing.T) {
+	filters := &config.PvrFilters{
+		Accepts: []string{
+			`any(Country, {# in ["us", "gb"]})`,
+			`Year >= 2000`,
+		},
+		Ignores: []config.PvrIgnore{
+			{When: `Title contains "WWE"`, Exclude: true},
+		},
+	}
+
+	f, err := NewFilters(filters)
+	if err != nil {
+		t.Fatalf("Failed compiling filter expressions: %v", err)
+	}
+
+	tests := []struct {
+		item  config.MediaItem
+		match string
+	}{
+		{config.MediaItem{Title: "Accepted", Country: []string{"us"}, Year: 2020}, ""},
+		{config.MediaItem{Title: "Wrong Country", Country: []string{"fr"}, Year: 2020}, "accepts[0]"},
+		{config.MediaItem{Title: "Too Old", Country: []string{"gb"}, Year: 1990}, "accepts[1]"},
+		{config.MediaItem{Title: "WWE Raw", Country: []string{"us"}, Year: 2020}, "ignores[0]"},
+	}
+
+	for _, test := range tests {
+		match, err := f.ExplainIgnore(&test.item)
+		if err != nil {
+			t.Errorf("Failed evaluating expressions for %q: %v", test.item.Title, err)
+			continue
+		}
+
+		result := ""
+		if match != nil {
+			result = fmt.Sprintf("%s[%d]", match.Type, match.Index)
+
+			if match.Exclude != (match.Type == FilterTypeIgnore) {
+				t.Errorf("Expected only the ignore match for %q to be an exclusion", test.item.Title)
+			}
+		}
+
+		if result != test.match {
+			t.Errorf("Expected match %q for %q but got %q", test.match, test.item.Title, result)
+		}
+	}
+}
@@ -1,14 +1,20 @@
 package pvr
 
 import (
+	"context"
+
 	"github.com/l3uddz/mediarr/config"
 )
 
 type Interface interface {
-	Init(MediaType) error
+	Init(context.Context, MediaType) error
+	GetVersion() string
 	ShouldIgnore(*config.MediaItem) (bool, error)
+	ExplainIgnore(*config.MediaItem) (*FilterMatch, error)
 
-	GetQualityProfileId(string) (int, error)
-	GetExistingMedia() (map[string]config.MediaItem, error)
-	AddMedia(*config.MediaItem) error
+	GetQualityProfileId(context.Context, string) (int, error)
+	GetExistingMedia(context.Context) (*config.MediaIndex, error)
+	LookupMedia(context.Context, *config.MediaItem) (bool, error)
+	AddMedia(context.Context, *config.MediaItem) error
+	ExcludeMedia(context.Context, *config.MediaItem) error
 }
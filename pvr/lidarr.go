@@ -0,0 +1,494 @@
+package pvr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/logger"
+	"github.com/l3uddz/mediarr/utils/web"
+
+	"github.com/imroc/req"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	lidarrMonitorOptions = []string{"all", "future", "missing", "existing", "first", "latest", "none"}
+)
+
+/* Structs */
+
+type Lidarr struct {
+	cfg               *config.Pvr
+	log               *logrus.Entry
+	apiUrl            string
+	reqHeaders        req.Header
+	qualityProfileId  int
+	metadataProfileId int
+	timeout           int
+
+	filters *Filters
+	tags    *Tags
+	tagIds  map[string]int
+	routes  *Routes
+
+	qualityProfileIds map[string]int
+
+	version      string
+	majorVersion int
+
+	lookups map[string]*LidarrArtistLookup
+
+	monitor string
+}
+
+type LidarrProfiles struct {
+	Name string
+	Id   int
+}
+
+type LidarrArtists struct {
+	ArtistName      string
+	ForeignArtistId string
+}
+
+type LidarrAddRequest struct {
+	ArtistName        string           `json:"artistName"`
+	ForeignArtistId   string           `json:"foreignArtistId"`
+	QualityProfileId  int              `json:"qualityProfileId"`
+	MetadataProfileId int              `json:"metadataProfileId"`
+	Images            []LidarrImage    `json:"images"`
+	Tags              []int            `json:"tags"`
+	Monitored         bool             `json:"monitored"`
+	RootFolderPath    string           `json:"rootFolderPath"`
+	AddOptions        LidarrAddOptions `json:"addOptions"`
+}
+
+type LidarrArtistLookup struct {
+	ArtistName      string
+	ForeignArtistId string
+	Images          []LidarrImage
+}
+
+type LidarrImage struct {
+	CoverType string `json:"coverType"`
+	Url       string `json:"url,omitempty"`
+	RemoteUrl string `json:"remoteUrl,omitempty"`
+}
+
+type LidarrExclusion struct {
+	Id         int    `json:"id,omitempty"`
+	ForeignId  string `json:"foreignId"`
+	ArtistName string `json:"artistName"`
+}
+
+type LidarrAddOptions struct {
+	Monitor                string `json:"monitor"`
+	SearchForMissingAlbums bool   `json:"searchForMissingAlbums"`
+}
+
+/* Initializer */
+
+func NewLidarr(name string, c *config.Pvr) *Lidarr {
+	// set headers
+	reqHeaders := req.Header{
+		"X-Api-Key": c.ApiKey,
+	}
+
+	return &Lidarr{
+		cfg:        c,
+		log:        logger.GetLogger(name),
+		apiUrl:     getApiUrl(c.URL, "v1"),
+		reqHeaders: reqHeaders,
+		timeout:    pvrDefaultTimeout,
+		lookups:    make(map[string]*LidarrArtistLookup),
+		tagIds:     make(map[string]int),
+	}
+}
+
+/* Private */
+
+func (p *Lidarr) compileExpressions() error {
+	filters, err := NewFilters(&p.cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	p.filters = filters
+
+	tags, err := NewTags(p.cfg.Tags)
+	if err != nil {
+		return err
+	}
+
+	p.tags = tags
+
+	routes, err := NewRoutes(p.cfg.Routes)
+	if err != nil {
+		return err
+	}
+
+	p.routes = routes
+	return nil
+}
+
+func (p *Lidarr) validateAddOptions() error {
+	var err error
+
+	// validate monitor option
+	if p.monitor, err = getOption("monitor", p.cfg.AddOptions.Monitor, "all", lidarrMonitorOptions); err != nil {
+		return err
+	}
+
+	// warn about radarr only options
+	if p.cfg.AddOptions.MinimumAvailability != "" {
+		p.log.Warn("Ignoring minimum_availability, it is only supported by radarr")
+	}
+
+	for pos, route := range p.cfg.Routes {
+		if route.MinimumAvailability != "" {
+			p.log.Warnf("Ignoring minimum_availability of route %d, it is only supported by radarr", pos)
+		}
+	}
+
+	return nil
+}
+
+func (p *Lidarr) getProfileId(ctx context.Context, profileType string, profileName string) (int, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, profileType+"profile"), p.timeout,
+		p.reqHeaders, &pvrDefaultRetry)
+	if err != nil {
+		return 0, fmt.Errorf("failed retrieving %s profiles api response", profileType)
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return 0, fmt.Errorf("failed retrieving valid %s profiles api response: %s", profileType,
+			resp.Response().Status)
+	}
+
+	// decode response
+	var s []LidarrProfiles
+	if err := resp.ToJSON(&s); err != nil {
+		return 0, errors.WithMessagef(err, "failed decoding %s profiles api response", profileType)
+	}
+
+	// find profile
+	for _, profile := range s {
+		if strings.EqualFold(profile.Name, profileName) {
+			return profile.Id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("failed finding %s profile: %q", profileType, profileName)
+}
+
+func (p *Lidarr) lookupArtist(ctx context.Context, mbId string) (*LidarrArtistLookup, error) {
+	// have we already looked up this item?
+	if lookup, ok := p.lookups[mbId]; ok {
+		return lookup, nil
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "artist", "lookup"), p.timeout, p.reqHeaders,
+		req.Param{"term": "lidarr:" + mbId}, &pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving artist lookup api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid artist lookup api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []LidarrArtistLookup
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding artist lookup api response")
+	}
+
+	// find artist (nil when it does not exist upstream)
+	var lookup *LidarrArtistLookup
+	for pos, artist := range s {
+		if strings.EqualFold(artist.ForeignArtistId, mbId) {
+			lookup = &s[pos]
+			break
+		}
+	}
+
+	p.lookups[mbId] = lookup
+	return lookup, nil
+}
+
+func (p *Lidarr) addExclusions(ctx context.Context, existingMediaItems *config.MediaIndex) error {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "importlistexclusion"), p.timeout,
+		p.reqHeaders, &pvrDefaultRetry)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving exclusions api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return fmt.Errorf("failed retrieving valid exclusions api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []LidarrExclusion
+	if err := resp.ToJSON(&s); err != nil {
+		return errors.WithMessage(err, "failed decoding exclusions api response")
+	}
+
+	// add excluded items
+	for _, item := range s {
+		if item.ForeignId == "" {
+			continue
+		}
+
+		existingMediaItems.AddExcluded(config.MediaItem{
+			Provider: "lidarr",
+			MbId:     item.ForeignId,
+			Title:    item.ArtistName,
+		})
+	}
+
+	return nil
+}
+
+/* Interface Implements */
+
+func (p *Lidarr) Init(ctx context.Context, mediaType MediaType) error {
+	// validate we support this media type
+	switch mediaType {
+	case MUSIC:
+		break
+	default:
+		return errors.New("unsupported media type")
+	}
+
+	// compile and validate filter expressions
+	if err := p.compileExpressions(); err != nil {
+		return err
+	}
+
+	// validate add options
+	if err := p.validateAddOptions(); err != nil {
+		return err
+	}
+
+	// detect version
+	status, err := getSystemStatus(ctx, p.apiUrl, p.reqHeaders, p.timeout)
+	if err != nil {
+		return err
+	}
+
+	p.version = status.Version
+	if p.majorVersion, err = getMajorVersion(status.Version); err != nil {
+		return err
+	} else if p.majorVersion < 1 {
+		return fmt.Errorf("unsupported lidarr version: %s", p.version)
+	}
+
+	p.log.WithField("version", p.version).Info("Detected version")
+
+	// load tags
+	if len(p.cfg.Tags) > 0 || p.routes.HasTags() {
+		if p.tagIds, err = getTags(ctx, p.apiUrl, p.reqHeaders, p.timeout); err != nil {
+			return err
+		}
+	}
+
+	// find quality profiles
+	if p.qualityProfileIds, err = getQualityProfileIds(ctx, p.log, p.cfg.QualityProfile, p.routes,
+		p.GetQualityProfileId); err != nil {
+		return err
+	}
+
+	p.qualityProfileId = p.qualityProfileIds[strings.ToLower(p.cfg.QualityProfile)]
+
+	// find metadata profile
+	if id, err := p.getProfileId(ctx, "metadata", p.cfg.MetadataProfile); err != nil {
+		return err
+	} else {
+		p.metadataProfileId = id
+
+		p.log.WithFields(logrus.Fields{
+			"metadata_name": p.cfg.MetadataProfile,
+			"metadata_id":   p.metadataProfileId,
+		}).Info("Found metadata profile")
+	}
+
+	return nil
+}
+
+func (p *Lidarr) LookupMedia(ctx context.Context, item *config.MediaItem) (bool, error) {
+	if item.MbId == "" {
+		return false, nil
+	}
+
+	lookup, err := p.lookupArtist(ctx, item.MbId)
+	if err != nil {
+		return false, err
+	}
+
+	return lookup != nil, nil
+}
+
+func (p *Lidarr) GetVersion() string {
+	return p.version
+}
+
+func (p *Lidarr) ShouldIgnore(mediaItem *config.MediaItem) (bool, error) {
+	match, err := p.ExplainIgnore(mediaItem)
+	if err != nil {
+		return true, err
+	}
+
+	return match != nil, nil
+}
+
+func (p *Lidarr) ExplainIgnore(mediaItem *config.MediaItem) (*FilterMatch, error) {
+	return p.filters.ExplainIgnore(mediaItem)
+}
+
+func (p *Lidarr) GetQualityProfileId(ctx context.Context, profileName string) (int, error) {
+	return p.getProfileId(ctx, "quality", profileName)
+}
+
+func (p *Lidarr) AddMedia(ctx context.Context, item *config.MediaItem) error {
+	// lookup artist
+	lookup, err := p.lookupArtist(ctx, item.MbId)
+	if err != nil {
+		return errors.WithMessage(err, "failed looking up artist")
+	} else if lookup == nil {
+		return fmt.Errorf("failed finding artist with musicbrainz id: %q", item.MbId)
+	}
+
+	// route item
+	route, err := p.routes.Match(item)
+	if err != nil {
+		return errors.WithMessage(err, "failed routing item")
+	}
+
+	qualityProfileId, rootFolder := p.qualityProfileId, p.cfg.RootFolder
+	monitored := getBoolOption(p.cfg.AddOptions.Monitored, true)
+
+	if route != nil {
+		p.log.Debugf("Routing %s via %s", item.String(), route.String())
+
+		if route.QualityProfile != "" {
+			qualityProfileId = p.qualityProfileIds[strings.ToLower(route.QualityProfile)]
+		}
+		if route.RootFolder != "" {
+			rootFolder = route.RootFolder
+		}
+		if route.Monitored != nil {
+			monitored = *route.Monitored
+		}
+	}
+
+	// resolve tags
+	tagIds, err := getTagIds(ctx, p.log, p.apiUrl, p.reqHeaders, p.timeout, p.tagIds, item, p.tags,
+		route.GetCompiledTags())
+	if err != nil {
+		return errors.WithMessage(err, "failed resolving tags")
+	}
+
+	// set request params
+	params := LidarrAddRequest{
+		ArtistName:        lookup.ArtistName,
+		ForeignArtistId:   lookup.ForeignArtistId,
+		QualityProfileId:  qualityProfileId,
+		MetadataProfileId: p.metadataProfileId,
+		Images:            lookup.Images,
+		Tags:              tagIds,
+		Monitored:         monitored,
+		RootFolderPath:    rootFolder,
+		AddOptions: LidarrAddOptions{
+			Monitor:                p.monitor,
+			SearchForMissingAlbums: getBoolOption(p.cfg.AddOptions.Search, true),
+		},
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "artist"), p.timeout, p.reqHeaders,
+		req.BodyJSON(params))
+	if err != nil {
+		return errors.New("failed retrieving add artist api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 && resp.Response().StatusCode != 201 {
+		return fmt.Errorf("failed retrieving valid add artist api response: %s", resp.Response().Status)
+	}
+
+	return nil
+}
+
+func (p *Lidarr) ExcludeMedia(ctx context.Context, item *config.MediaItem) error {
+	if item.MbId == "" {
+		return errors.New("failed excluding artist with no musicbrainz id")
+	}
+
+	// set request params
+	params := LidarrExclusion{
+		ForeignId:  item.MbId,
+		ArtistName: item.Title,
+	}
+
+	return addExclusion(ctx, p.apiUrl, p.reqHeaders, p.timeout, "importlistexclusion", params)
+}
+
+func (p *Lidarr) GetExistingMedia(ctx context.Context) (*config.MediaIndex, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "artist"), p.timeout, p.reqHeaders,
+		&pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.New("failed retrieving artists api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid artists api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []LidarrArtists
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding artists api response")
+	}
+
+	// parse response
+	existingMediaItems := config.NewMediaIndex()
+
+	for _, item := range s {
+		if item.ForeignArtistId == "" {
+			continue
+		}
+
+		existingMediaItems.Add(config.MediaItem{
+			Provider: "lidarr",
+			MbId:     item.ForeignArtistId,
+			Title:    item.ArtistName,
+		})
+	}
+
+	// add exclusions
+	if err := p.addExclusions(ctx, existingMediaItems); err != nil {
+		return nil, err
+	}
+
+	p.log.WithFields(logrus.Fields{
+		"artists":  existingMediaItems.Size(),
+		"excluded": existingMediaItems.ExcludedSize(),
+	}).Info("Retrieved media items")
+	return existingMediaItems, nil
+}
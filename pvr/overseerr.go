@@ -0,0 +1,497 @@
+package pvr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/logger"
+	"github.com/l3uddz/mediarr/utils/web"
+
+	"github.com/imroc/req"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	overseerrPageSize = 100
+
+	overseerrRequestPending   = 1
+	overseerrRequestApproved  = 2
+	overseerrRequestDeclined  = 3
+	overseerrRequestFailed    = 4
+	overseerrRequestCompleted = 5
+
+	overseerrMediaUnknown            = 1
+	overseerrMediaPending            = 2
+	overseerrMediaProcessing         = 3
+	overseerrMediaPartiallyAvailable = 4
+	overseerrMediaAvailable          = 5
+	overseerrMediaBlacklisted        = 6
+)
+
+/* Structs */
+
+type Overseerr struct {
+	cfg        *config.Pvr
+	log        *logrus.Entry
+	apiUrl     string
+	reqHeaders req.Header
+	timeout    int
+
+	filters *Filters
+
+	mediaType string
+
+	version      string
+	majorVersion int
+
+	lookups map[string]*OverseerrLookup
+}
+
+type OverseerrStatus struct {
+	Version string
+}
+
+type OverseerrPageInfo struct {
+	Pages int
+	Page  int
+}
+
+type OverseerrMedia struct {
+	MediaType string
+	TmdbId    int
+	TvdbId    int
+	ImdbId    string
+	Status    int
+}
+
+type OverseerrMediaResponse struct {
+	PageInfo OverseerrPageInfo
+	Results  []OverseerrMedia
+}
+
+type OverseerrMediaRequest struct {
+	Id     int
+	Status int
+	Media  OverseerrMedia
+}
+
+type OverseerrRequestsResponse struct {
+	PageInfo OverseerrPageInfo
+	Results  []OverseerrMediaRequest
+}
+
+type OverseerrLookup struct {
+	Id          int
+	Title       string
+	Name        string
+	ExternalIds struct {
+		TvdbId int
+	}
+}
+
+type OverseerrRequest struct {
+	MediaType string `json:"mediaType"`
+	MediaId   int    `json:"mediaId"`
+	TvdbId    int    `json:"tvdbId,omitempty"`
+	Seasons   string `json:"seasons,omitempty"`
+	Is4k      bool   `json:"is4k"`
+	RootPath  string `json:"rootFolder,omitempty"`
+	UserId    int    `json:"userId,omitempty"`
+}
+
+type OverseerrRequestResponse struct {
+	Id     int
+	Status int
+}
+
+/* Initializer */
+
+func NewOverseerr(name string, c *config.Pvr) *Overseerr {
+	// set headers
+	reqHeaders := req.Header{
+		"X-Api-Key": c.ApiKey,
+	}
+
+	return &Overseerr{
+		cfg:        c,
+		log:        logger.GetLogger(name),
+		apiUrl:     getApiUrl(c.URL, "v1"),
+		reqHeaders: reqHeaders,
+		timeout:    pvrDefaultTimeout,
+		lookups:    make(map[string]*OverseerrLookup),
+	}
+}
+
+/* Private */
+
+func (p *Overseerr) validateAddOptions() error {
+	// pending requests must be filed by a user without auto-approve permission
+	if !getBoolOption(p.cfg.AddOptions.Approve, true) && p.cfg.AddOptions.RequestUser == 0 {
+		return errors.New("add_options.request_user must be set when add_options.approve is false")
+	}
+
+	// overseerr has no exclusion list to push ignored items to
+	for _, ignore := range p.cfg.Filters.Ignores {
+		if ignore.Exclude {
+			return fmt.Errorf("filters.ignores cannot exclude items, exclusions are not supported by overseerr: %q",
+				ignore.When)
+		}
+	}
+
+	// warn about settings managed by overseerr
+	if p.cfg.QualityProfile != "" || p.cfg.LanguageProfile != "" {
+		p.log.Warn("Ignoring quality_profile and language_profile, they are managed by overseerr")
+	}
+
+	if len(p.cfg.Tags) > 0 || len(p.cfg.Routes) > 0 {
+		p.log.Warn("Ignoring tags and routes, they are not supported by overseerr")
+	}
+
+	return nil
+}
+
+func (p *Overseerr) getStatus(ctx context.Context) (*OverseerrStatus, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "status"), p.timeout, p.reqHeaders,
+		&pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving status api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid status api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s OverseerrStatus
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding status api response")
+	}
+
+	return &s, nil
+}
+
+func (p *Overseerr) lookupMedia(ctx context.Context, tmdbId string) (*OverseerrLookup, error) {
+	// have we already looked up this item?
+	if lookup, ok := p.lookups[tmdbId]; ok {
+		return lookup, nil
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, p.mediaType, tmdbId), p.timeout,
+		p.reqHeaders, &pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving lookup api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response (nil when it does not exist upstream)
+	var lookup *OverseerrLookup
+
+	switch resp.Response().StatusCode {
+	case 200:
+		var s OverseerrLookup
+		if err := resp.ToJSON(&s); err != nil {
+			return nil, errors.WithMessage(err, "failed decoding lookup api response")
+		}
+
+		lookup = &s
+	case 404:
+		// tmdb does not know the id
+		break
+	default:
+		return nil, fmt.Errorf("failed retrieving valid lookup api response: %s", resp.Response().Status)
+	}
+
+	p.lookups[tmdbId] = lookup
+	return lookup, nil
+}
+
+func (p *Overseerr) addMediaItem(existingMediaItems *config.MediaIndex, media OverseerrMedia) {
+	if media.MediaType != p.mediaType || media.TmdbId < 1 {
+		return
+	}
+
+	mediaItem := config.MediaItem{
+		Provider: "overseerr",
+		TmdbId:   strconv.Itoa(media.TmdbId),
+		ImdbId:   media.ImdbId,
+	}
+
+	if media.TvdbId > 0 {
+		mediaItem.TvdbId = strconv.Itoa(media.TvdbId)
+	}
+
+	switch media.Status {
+	case overseerrMediaPending, overseerrMediaProcessing, overseerrMediaPartiallyAvailable, overseerrMediaAvailable:
+		// requested or available
+		existingMediaItems.Add(mediaItem)
+	case overseerrMediaBlacklisted:
+		// jellyseerr can blacklist media
+		existingMediaItems.AddExcluded(mediaItem)
+	default:
+		// unknown media, e.g. removed, can be requested again
+		break
+	}
+}
+
+func (p *Overseerr) addRequestItem(existingMediaItems *config.MediaIndex, request OverseerrMediaRequest) {
+	switch request.Status {
+	case overseerrRequestPending, overseerrRequestApproved, overseerrRequestCompleted:
+		p.addMediaItem(existingMediaItems, request.Media)
+	default:
+		// declined and failed requests can be requested again
+		break
+	}
+}
+
+func (p *Overseerr) addExistingMedia(ctx context.Context, existingMediaItems *config.MediaIndex) error {
+	for page := 0; ; page++ {
+		// send request
+		resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "media"), p.timeout, p.reqHeaders,
+			req.Param{"take": overseerrPageSize, "skip": page * overseerrPageSize}, &pvrDefaultRetry)
+		if err != nil {
+			return errors.WithMessage(err, "failed retrieving media api response")
+		}
+
+		// validate response
+		if resp.Response().StatusCode != 200 {
+			web.DrainAndClose(resp.Response().Body)
+			return fmt.Errorf("failed retrieving valid media api response: %s", resp.Response().Status)
+		}
+
+		// decode response
+		var s OverseerrMediaResponse
+		if err := resp.ToJSON(&s); err != nil {
+			web.DrainAndClose(resp.Response().Body)
+			return errors.WithMessage(err, "failed decoding media api response")
+		}
+
+		web.DrainAndClose(resp.Response().Body)
+
+		for _, media := range s.Results {
+			p.addMediaItem(existingMediaItems, media)
+		}
+
+		if s.PageInfo.Page >= s.PageInfo.Pages {
+			return nil
+		}
+	}
+}
+
+func (p *Overseerr) addExistingRequests(ctx context.Context, existingMediaItems *config.MediaIndex) error {
+	for page := 0; ; page++ {
+		// send request
+		resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "request"), p.timeout, p.reqHeaders,
+			req.Param{"take": overseerrPageSize, "skip": page * overseerrPageSize, "filter": "all"},
+			&pvrDefaultRetry)
+		if err != nil {
+			return errors.WithMessage(err, "failed retrieving requests api response")
+		}
+
+		// validate response
+		if resp.Response().StatusCode != 200 {
+			web.DrainAndClose(resp.Response().Body)
+			return fmt.Errorf("failed retrieving valid requests api response: %s", resp.Response().Status)
+		}
+
+		// decode response
+		var s OverseerrRequestsResponse
+		if err := resp.ToJSON(&s); err != nil {
+			web.DrainAndClose(resp.Response().Body)
+			return errors.WithMessage(err, "failed decoding requests api response")
+		}
+
+		web.DrainAndClose(resp.Response().Body)
+
+		for _, request := range s.Results {
+			p.addRequestItem(existingMediaItems, request)
+		}
+
+		if s.PageInfo.Page >= s.PageInfo.Pages {
+			return nil
+		}
+	}
+}
+
+func (p *Overseerr) approveRequest(ctx context.Context, id int) error {
+	// send request
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "request", strconv.Itoa(id), "approve"),
+		p.timeout, p.reqHeaders)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving approve request api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return fmt.Errorf("failed retrieving valid approve request api response: %s", resp.Response().Status)
+	}
+
+	return nil
+}
+
+/* Interface Implements */
+
+func (p *Overseerr) Init(ctx context.Context, mediaType MediaType) error {
+	// validate we support this media type
+	switch mediaType {
+	case MOVIE:
+		p.mediaType = "movie"
+	case SHOW:
+		p.mediaType = "tv"
+	default:
+		return errors.New("unsupported media type")
+	}
+
+	// compile and validate filter expressions
+	filters, err := NewFilters(&p.cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	p.filters = filters
+
+	// validate add options
+	if err := p.validateAddOptions(); err != nil {
+		return err
+	}
+
+	// detect version
+	status, err := p.getStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.version = status.Version
+	if p.majorVersion, err = getMajorVersion(status.Version); err != nil {
+		return err
+	} else if p.majorVersion < 1 {
+		return fmt.Errorf("unsupported overseerr version: %s", p.version)
+	}
+
+	p.log.WithField("version", p.version).Info("Detected version")
+	return nil
+}
+
+func (p *Overseerr) LookupMedia(ctx context.Context, item *config.MediaItem) (bool, error) {
+	// overseerr requests are made by tmdb id
+	if item.TmdbId == "" {
+		p.log.Debugf("Ignoring, no TmdbId: %s", item.String())
+		return false, nil
+	}
+
+	lookup, err := p.lookupMedia(ctx, item.TmdbId)
+	if err != nil {
+		return false, err
+	}
+
+	return lookup != nil, nil
+}
+
+func (p *Overseerr) GetVersion() string {
+	return p.version
+}
+
+func (p *Overseerr) ShouldIgnore(mediaItem *config.MediaItem) (bool, error) {
+	match, err := p.ExplainIgnore(mediaItem)
+	if err != nil {
+		return true, err
+	}
+
+	return match != nil, nil
+}
+
+func (p *Overseerr) ExplainIgnore(mediaItem *config.MediaItem) (*FilterMatch, error) {
+	return p.filters.ExplainIgnore(mediaItem)
+}
+
+func (p *Overseerr) GetQualityProfileId(_ context.Context, _ string) (int, error) {
+	return 0, errors.New("quality profiles are managed by overseerr")
+}
+
+func (p *Overseerr) AddMedia(ctx context.Context, item *config.MediaItem) error {
+	// lookup media
+	lookup, err := p.lookupMedia(ctx, item.TmdbId)
+	if err != nil {
+		return errors.WithMessage(err, "failed looking up media")
+	} else if lookup == nil {
+		return fmt.Errorf("failed finding media with tmdb id: %q", item.TmdbId)
+	}
+
+	// set request params
+	params := OverseerrRequest{
+		MediaType: p.mediaType,
+		MediaId:   lookup.Id,
+		Is4k:      p.cfg.AddOptions.Is4k,
+		RootPath:  p.cfg.RootFolder,
+		UserId:    p.cfg.AddOptions.RequestUser,
+	}
+
+	if p.mediaType == "tv" {
+		params.TvdbId = lookup.ExternalIds.TvdbId
+		params.Seasons = "all"
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "request"), p.timeout, p.reqHeaders,
+		req.BodyJSON(params))
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving request api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 && resp.Response().StatusCode != 201 {
+		return fmt.Errorf("failed retrieving valid request api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s OverseerrRequestResponse
+	if err := resp.ToJSON(&s); err != nil {
+		return errors.WithMessage(err, "failed decoding request api response")
+	}
+
+	// approve pending request
+	if s.Status == overseerrRequestPending && getBoolOption(p.cfg.AddOptions.Approve, true) {
+		if err := p.approveRequest(ctx, s.Id); err != nil {
+			return errors.WithMessagef(err, "failed approving request %d", s.Id)
+		}
+	}
+
+	return nil
+}
+
+func (p *Overseerr) ExcludeMedia(_ context.Context, _ *config.MediaItem) error {
+	return errors.New("exclusions are not supported by overseerr")
+}
+
+func (p *Overseerr) GetExistingMedia(ctx context.Context) (*config.MediaIndex, error) {
+	existingMediaItems := config.NewMediaIndex()
+
+	// add known media, e.g. available or previously requested
+	if err := p.addExistingMedia(ctx, existingMediaItems); err != nil {
+		return nil, err
+	}
+
+	// add requests
+	if err := p.addExistingRequests(ctx, existingMediaItems); err != nil {
+		return nil, err
+	}
+
+	sizeField := "movies"
+	if p.mediaType == "tv" {
+		sizeField = "shows"
+	}
+
+	p.log.WithFields(logrus.Fields{
+		sizeField:  existingMediaItems.Size(),
+		"excluded": existingMediaItems.ExcludedSize(),
+	}).Info("Retrieved media items")
+	return existingMediaItems, nil
+}
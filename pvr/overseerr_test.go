@@ -0,0 +1,64 @@
+package pvr
+
+import (
+	"testing"
+
+	"github.com/l3uddz/mediarr/config"
+)
+
+/* Test Overseerr Status */
+
+func TestOverseerrStatus(t *testing.T) {
+	p := &Overseerr{mediaType: "movie"}
+
+	tests := []struct {
+		name          string
+		mediaStatus   int
+		requestStatus int
+		mediaExisting bool
+		existing      bool
+		excluded      bool
+	}{
+		{"unknown", overseerrMediaUnknown, overseerrRequestCompleted, false, false, false},
+		{"pending", overseerrMediaPending, overseerrRequestPending, true, true, false},
+		{"processing", overseerrMediaProcessing, overseerrRequestApproved, true, true, false},
+		{"partially available", overseerrMediaPartiallyAvailable, overseerrRequestCompleted, true, true, false},
+		{"available", overseerrMediaAvailable, overseerrRequestCompleted, true, true, false},
+		{"blacklisted", overseerrMediaBlacklisted, overseerrRequestCompleted, false, false, true},
+		{"declined", overseerrMediaPending, overseerrRequestDeclined, true, false, false},
+		{"failed", overseerrMediaProcessing, overseerrRequestFailed, true, false, false},
+	}
+
+	for _, test := range tests {
+		media := OverseerrMedia{MediaType: "movie", TmdbId: 603, Status: test.mediaStatus}
+		item := &config.MediaItem{TmdbId: "603"}
+
+		// media
+		existingMediaItems := config.NewMediaIndex()
+		p.addMediaItem(existingMediaItems, media)
+
+		if exists := existingMediaItems.Size() > 0; exists != test.mediaExisting {
+			t.Errorf("Expected existing media for %q to be %v, got %v", test.name, test.mediaExisting, exists)
+		}
+
+		// requests
+		existingMediaItems = config.NewMediaIndex()
+		p.addRequestItem(existingMediaItems, OverseerrMediaRequest{Id: 1, Status: test.requestStatus, Media: media})
+
+		if exists := existingMediaItems.Size() > 0; exists != test.existing {
+			t.Errorf("Expected existing request for %q to be %v, got %v", test.name, test.existing, exists)
+		}
+
+		if excluded := existingMediaItems.IsExcluded(item); excluded != test.excluded {
+			t.Errorf("Expected excluded request for %q to be %v, got %v", test.name, test.excluded, excluded)
+		}
+	}
+
+	// other media types are skipped
+	existingMediaItems := config.NewMediaIndex()
+	p.addMediaItem(existingMediaItems, OverseerrMedia{MediaType: "tv", TmdbId: 1399, Status: overseerrMediaAvailable})
+
+	if existingMediaItems.Size() > 0 {
+		t.Errorf("Expected tv media to be skipped for a movie pvr")
+	}
+}
@@ -1,14 +1,20 @@
 package pvr
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/l3uddz/mediarr/config"
 	"github.com/l3uddz/mediarr/utils/web"
 
+	"github.com/imroc/req"
 	"github.com/jpillora/backoff"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -16,6 +22,9 @@ var (
 	pvrDefaultRetry   = web.Retry{
 		MaxAttempts: 6,
 		RetryableStatusCodes: []int{
+			429,
+			502,
+			503,
 			504,
 		},
 		Backoff: backoff.Backoff{
@@ -26,6 +35,18 @@ var (
 	}
 )
 
+/* Structs */
+
+// the sonarr, radarr and lidarr apis only differ in version and endpoint names
+type ArrSystemStatus struct {
+	Version string
+}
+
+type ArrTag struct {
+	Id    int    `json:"id,omitempty"`
+	Label string `json:"label"`
+}
+
 /* Public */
 
 func Get(pvrName string, pvrType string, pvrConfig *config.Pvr) (Interface, error) {
@@ -34,9 +55,219 @@ func Get(pvrName string, pvrType string, pvrConfig *config.Pvr) (Interface, erro
 		return NewSonarr(pvrName, pvrConfig), nil
 	case "radarr":
 		return NewRadarr(pvrName, pvrConfig), nil
+	case "lidarr":
+		return NewLidarr(pvrName, pvrConfig), nil
+	case "overseerr", "jellyseerr":
+		return NewOverseerr(pvrName, pvrConfig), nil
 	default:
 		break
 	}
 
 	return nil, fmt.Errorf("unsupported pvr type provided: %q", pvrType)
 }
+
+/* Private */
+
+func getApiUrl(baseUrl string, version string) string {
+	// strip any api path from the configured url
+	if u, err := url.Parse(baseUrl); err == nil {
+		if pos := strings.Index(strings.ToLower(u.Path), "/api"); pos >= 0 {
+			u.Path = u.Path[:pos]
+			baseUrl = u.String()
+		}
+	}
+
+	return web.JoinURL(baseUrl, "api", version)
+}
+
+func getMajorVersion(version string) (int, error) {
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing version: %q", version)
+	}
+
+	return major, nil
+}
+
+func getOption(name string, value string, defaultValue string, options []string) (string, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid %s %q, valid values: %s", name, value, strings.Join(options, ", "))
+}
+
+func getBoolOption(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+
+	return *value
+}
+
+func getSystemStatus(ctx context.Context, apiUrl string, reqHeaders req.Header, timeout int) (*ArrSystemStatus, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(apiUrl, "system", "status"), timeout, reqHeaders,
+		&pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving system status api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid system status api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s ArrSystemStatus
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding system status api response")
+	}
+
+	return &s, nil
+}
+
+func getQualityProfileIds(ctx context.Context, log *logrus.Entry, profileName string, routes *Routes,
+	getQualityProfileId func(context.Context, string) (int, error)) (map[string]int, error) {
+	// find quality profile
+	id, err := getQualityProfileId(ctx, profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(logrus.Fields{
+		"quality_name": profileName,
+		"quality_id":   id,
+	}).Info("Found quality profile")
+
+	// find route quality profiles
+	qualityProfileIds := map[string]int{
+		strings.ToLower(profileName): id,
+	}
+
+	for _, profile := range routes.GetQualityProfiles() {
+		if _, ok := qualityProfileIds[strings.ToLower(profile)]; ok {
+			continue
+		}
+
+		id, err := getQualityProfileId(ctx, profile)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed finding route quality profile")
+		}
+
+		qualityProfileIds[strings.ToLower(profile)] = id
+
+		log.WithFields(logrus.Fields{
+			"quality_name": profile,
+			"quality_id":   id,
+		}).Info("Found route quality profile")
+	}
+
+	return qualityProfileIds, nil
+}
+
+func getTags(ctx context.Context, apiUrl string, reqHeaders req.Header, timeout int) (map[string]int, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(apiUrl, "tag"), timeout, reqHeaders, &pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving tags api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid tags api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []ArrTag
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding tags api response")
+	}
+
+	// map tag labels to ids
+	tagIds := make(map[string]int)
+	for _, tag := range s {
+		tagIds[strings.ToLower(tag.Label)] = tag.Id
+	}
+
+	return tagIds, nil
+}
+
+func createTag(ctx context.Context, apiUrl string, reqHeaders req.Header, timeout int, label string) (int, error) {
+	// send request
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(apiUrl, "tag"), timeout, reqHeaders,
+		req.BodyJSON(ArrTag{Label: label}))
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed retrieving create tag api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 && resp.Response().StatusCode != 201 {
+		return 0, fmt.Errorf("failed retrieving valid create tag api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s ArrTag
+	if err := resp.ToJSON(&s); err != nil {
+		return 0, errors.WithMessage(err, "failed decoding create tag api response")
+	}
+
+	return s.Id, nil
+}
+
+func getTagIds(ctx context.Context, log *logrus.Entry, apiUrl string, reqHeaders req.Header, timeout int,
+	tagIds map[string]int, item *config.MediaItem, tags ...*Tags) ([]int, error) {
+	names, err := getTagNames(item, tags...)
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve tag ids, creating missing tags
+	ids := make([]int, 0)
+	for _, name := range names {
+		id, ok := tagIds[name]
+		if !ok {
+			if id, err = createTag(ctx, apiUrl, reqHeaders, timeout, name); err != nil {
+				return nil, errors.WithMessagef(err, "failed creating tag: %q", name)
+			}
+
+			tagIds[name] = id
+
+			log.WithFields(logrus.Fields{
+				"tag_name": name,
+				"tag_id":   id,
+			}).Info("Created tag")
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func addExclusion(ctx context.Context, apiUrl string, reqHeaders req.Header, timeout int, endpoint string,
+	exclusion interface{}) error {
+	// send request
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(apiUrl, endpoint), timeout, reqHeaders,
+		req.BodyJSON(exclusion))
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving add exclusion api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 && resp.Response().StatusCode != 201 {
+		return fmt.Errorf("failed retrieving valid add exclusion api response: %s", resp.Response().Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package pvr
+
+import "testing"
+
+/* Test Api Url */
+
+func TestGetApiUrl(t *testing.T) {
+	tests := []struct {
+		url     string
+		version string
+		want    string
+	}{
+		{"https://sonarr.domain.com", "v3", "https://sonarr.domain.com/api/v3"},
+		{"https://domain.com/sonarr/", "v3", "https://domain.com/sonarr/api/v3"},
+		{"https://domain.com/sonarr/api", "v3", "https://domain.com/sonarr/api/v3"},
+		{"https://domain.com/sonarr/api/v3", "v3", "https://domain.com/sonarr/api/v3"},
+		{"https://api.domain.com", "v3", "https://api.domain.com/api/v3"},
+		{"https://domain.com/lidarr/api/v1", "v1", "https://domain.com/lidarr/api/v1"},
+	}
+
+	for _, test := range tests {
+		if got := getApiUrl(test.url, test.version); got != test.want {
+			t.Errorf("Expected api url %q for %q, got %q", test.want, test.url, got)
+		}
+	}
+}
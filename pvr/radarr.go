@@ -1,22 +1,24 @@
 package pvr
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/l3uddz/mediarr/config"
 	"github.com/l3uddz/mediarr/logger"
 	"github.com/l3uddz/mediarr/utils/web"
 
-	"github.com/antonmedv/expr"
-	"github.com/antonmedv/expr/vm"
 	"github.com/imroc/req"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	radarrMinimumAvailabilities = []string{"announced", "inCinemas", "released", "preDB"}
+)
+
 /* Structs */
 
 type Radarr struct {
@@ -27,11 +29,19 @@ type Radarr struct {
 	qualityProfileId int
 	timeout          int
 
-	ignoresExpr []*vm.Program
-}
+	filters *Filters
+	tags    *Tags
+	tagIds  map[string]int
+	routes  *Routes
+
+	qualityProfileIds map[string]int
+
+	version      string
+	majorVersion int
 
-type RadarrSystemStatus struct {
-	Version string
+	lookups map[string]*RadarrMovieLookup
+
+	minimumAvailability string
 }
 
 type RadarrQualityProfiles struct {
@@ -51,12 +61,36 @@ type RadarrAddRequest struct {
 	TitleSlug           string           `json:"titleSlug"`
 	Year                int              `json:"year"`
 	QualityProfileId    int              `json:"qualityProfileId"`
-	Images              []string         `json:"images"`
+	Images              []RadarrImage    `json:"images"`
+	Tags                []int            `json:"tags"`
 	Monitored           bool             `json:"monitored"`
 	RootFolderPath      string           `json:"rootFolderPath"`
 	MinimumAvailability string           `json:"minimumAvailability"`
 	AddOptions          RadarrAddOptions `json:"addOptions"`
 	TmdbId              int              `json:"tmdbId"`
+	ImdbId              string           `json:"imdbId,omitempty"`
+}
+
+type RadarrMovieLookup struct {
+	Title     string
+	TitleSlug string
+	Year      int
+	TmdbId    int
+	ImdbId    string
+	Images    []RadarrImage
+}
+
+type RadarrImage struct {
+	CoverType string `json:"coverType"`
+	Url       string `json:"url,omitempty"`
+	RemoteUrl string `json:"remoteUrl,omitempty"`
+}
+
+type RadarrExclusion struct {
+	Id         int    `json:"id,omitempty"`
+	TmdbId     int    `json:"tmdbId"`
+	MovieTitle string `json:"movieTitle"`
+	MovieYear  int    `json:"movieYear"`
 }
 
 type RadarrAddOptions struct {
@@ -68,14 +102,6 @@ type RadarrAddOptions struct {
 /* Initializer */
 
 func NewRadarr(name string, c *config.Pvr) *Radarr {
-	// set api url
-	apiUrl := ""
-	if strings.Contains(c.URL, "/api") {
-		apiUrl = c.URL
-	} else {
-		apiUrl = web.JoinURL(c.URL, "api", "v3")
-	}
-
 	// set headers
 	reqHeaders := req.Header{
 		"X-Api-Key": c.ApiKey,
@@ -84,25 +110,130 @@ func NewRadarr(name string, c *config.Pvr) *Radarr {
 	return &Radarr{
 		cfg:        c,
 		log:        logger.GetLogger(name),
-		apiUrl:     apiUrl,
+		apiUrl:     getApiUrl(c.URL, "v3"),
 		reqHeaders: reqHeaders,
 		timeout:    pvrDefaultTimeout,
+		lookups:    make(map[string]*RadarrMovieLookup),
+		tagIds:     make(map[string]int),
 	}
 }
 
 /* Private */
 
 func (p *Radarr) compileExpressions() error {
-	exprEnv := &config.ExprEnv{}
+	filters, err := NewFilters(&p.cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	p.filters = filters
+
+	tags, err := NewTags(p.cfg.Tags)
+	if err != nil {
+		return err
+	}
+
+	p.tags = tags
+
+	routes, err := NewRoutes(p.cfg.Routes)
+	if err != nil {
+		return err
+	}
+
+	p.routes = routes
+	return nil
+}
+
+func (p *Radarr) validateAddOptions() error {
+	var err error
+
+	// validate minimum availability
+	p.minimumAvailability, err = getOption("minimum_availability", p.cfg.AddOptions.MinimumAvailability,
+		"released", radarrMinimumAvailabilities)
+	if err != nil {
+		return err
+	}
 
-	// compile ignores
-	for _, ignoreExpr := range p.cfg.Filters.Ignores {
-		program, err := expr.Compile(ignoreExpr, expr.Env(exprEnv), expr.AsBool())
-		if err != nil {
-			return errors.Wrapf(err, "failed compiling ignore expression for: %q", ignoreExpr)
+	for pos, route := range p.cfg.Routes {
+		if _, err := getOption("minimum_availability", route.MinimumAvailability, "",
+			radarrMinimumAvailabilities); err != nil {
+			return errors.WithMessagef(err, "failed validating route %d", pos)
 		}
+	}
 
-		p.ignoresExpr = append(p.ignoresExpr, program)
+	return nil
+}
+
+func (p *Radarr) lookupMovie(ctx context.Context, tmdbId string) (*RadarrMovieLookup, error) {
+	// have we already looked up this item?
+	if lookup, ok := p.lookups[tmdbId]; ok {
+		return lookup, nil
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "movie", "lookup"), p.timeout, p.reqHeaders,
+		req.Param{"term": "tmdb:" + tmdbId}, &pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving movie lookup api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid movie lookup api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []RadarrMovieLookup
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding movie lookup api response")
+	}
+
+	// find movie (nil when it does not exist upstream)
+	var lookup *RadarrMovieLookup
+	for pos, movie := range s {
+		if strconv.Itoa(movie.TmdbId) == tmdbId {
+			lookup = &s[pos]
+			break
+		}
+	}
+
+	p.lookups[tmdbId] = lookup
+	return lookup, nil
+}
+
+func (p *Radarr) addExclusions(ctx context.Context, existingMediaItems *config.MediaIndex) error {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "exclusions"), p.timeout, p.reqHeaders,
+		&pvrDefaultRetry)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving exclusions api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return fmt.Errorf("failed retrieving valid exclusions api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []RadarrExclusion
+	if err := resp.ToJSON(&s); err != nil {
+		return errors.WithMessage(err, "failed decoding exclusions api response")
+	}
+
+	// add excluded items
+	for _, item := range s {
+		if item.TmdbId < 1 {
+			continue
+		}
+
+		existingMediaItems.AddExcluded(config.MediaItem{
+			Provider: "radarr",
+			TmdbId:   strconv.Itoa(item.TmdbId),
+			Title:    item.MovieTitle,
+			Year:     item.MovieYear,
+		})
 	}
 
 	return nil
@@ -110,7 +241,7 @@ func (p *Radarr) compileExpressions() error {
 
 /* Interface Implements */
 
-func (p *Radarr) Init(mediaType MediaType) error {
+func (p *Radarr) Init(ctx context.Context, mediaType MediaType) error {
 	// validate we support this media type
 	switch mediaType {
 	case MOVIE:
@@ -124,46 +255,77 @@ func (p *Radarr) Init(mediaType MediaType) error {
 		return err
 	}
 
-	// find quality profile
-	if id, err := p.GetQualityProfileId(p.cfg.QualityProfile); err != nil {
+	// validate add options
+	if err := p.validateAddOptions(); err != nil {
+		return err
+	}
+
+	// detect version
+	status, err := getSystemStatus(ctx, p.apiUrl, p.reqHeaders, p.timeout)
+	if err != nil {
 		return err
-	} else {
-		p.qualityProfileId = id
+	}
 
-		p.log.WithFields(logrus.Fields{
-			"quality_name": p.cfg.QualityProfile,
-			"quality_id":   p.qualityProfileId,
-		}).Info("Found quality profile")
+	p.version = status.Version
+	if p.majorVersion, err = getMajorVersion(status.Version); err != nil {
+		return err
+	} else if p.majorVersion < 3 {
+		return fmt.Errorf("unsupported radarr version: %s", p.version)
 	}
 
+	p.log.WithField("version", p.version).Info("Detected version")
+
+	// load tags
+	if len(p.cfg.Tags) > 0 || p.routes.HasTags() {
+		if p.tagIds, err = getTags(ctx, p.apiUrl, p.reqHeaders, p.timeout); err != nil {
+			return err
+		}
+	}
+
+	// find quality profiles
+	if p.qualityProfileIds, err = getQualityProfileIds(ctx, p.log, p.cfg.QualityProfile, p.routes,
+		p.GetQualityProfileId); err != nil {
+		return err
+	}
+
+	p.qualityProfileId = p.qualityProfileIds[strings.ToLower(p.cfg.QualityProfile)]
+
 	return nil
 }
 
-func (p *Radarr) ShouldIgnore(mediaItem *config.MediaItem) (bool, error) {
-	exprItem := config.GetExprEnv(mediaItem)
+func (p *Radarr) LookupMedia(ctx context.Context, item *config.MediaItem) (bool, error) {
+	if item.TmdbId == "" {
+		return false, nil
+	}
 
-	for _, expression := range p.ignoresExpr {
-		result, err := expr.Run(expression, exprItem)
-		if err != nil {
-			return true, errors.Wrap(err, "failed checking ignore expression")
-		}
+	lookup, err := p.lookupMovie(ctx, item.TmdbId)
+	if err != nil {
+		return false, err
+	}
 
-		expResult, ok := result.(bool)
-		if !ok {
-			return true, errors.New("failed type asserting ignore expression result")
-		}
+	return lookup != nil, nil
+}
 
-		if expResult {
-			return true, nil
-		}
+func (p *Radarr) GetVersion() string {
+	return p.version
+}
+
+func (p *Radarr) ShouldIgnore(mediaItem *config.MediaItem) (bool, error) {
+	match, err := p.ExplainIgnore(mediaItem)
+	if err != nil {
+		return true, err
 	}
 
-	return false, nil
+	return match != nil, nil
 }
 
-func (p *Radarr) GetQualityProfileId(profileName string) (int, error) {
+func (p *Radarr) ExplainIgnore(mediaItem *config.MediaItem) (*FilterMatch, error) {
+	return p.filters.ExplainIgnore(mediaItem)
+}
+
+func (p *Radarr) GetQualityProfileId(ctx context.Context, profileName string) (int, error) {
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "qualityprofile"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "qualityprofile"), p.timeout, p.reqHeaders,
 		&pvrDefaultRetry)
 	if err != nil {
 		return 0, errors.New("failed retrieving quality profiles api response")
@@ -191,33 +353,72 @@ func (p *Radarr) GetQualityProfileId(profileName string) (int, error) {
 	return 0, fmt.Errorf("failed finding quality profile: %q", profileName)
 }
 
-func (p *Radarr) AddMedia(item *config.MediaItem) error {
-	// convert TmdbId to int
-	tmdbId, err := strconv.Atoi(item.TmdbId)
+func (p *Radarr) AddMedia(ctx context.Context, item *config.MediaItem) error {
+	// lookup movie
+	lookup, err := p.lookupMovie(ctx, item.TmdbId)
+	if err != nil {
+		return errors.WithMessage(err, "failed looking up movie")
+	} else if lookup == nil {
+		return fmt.Errorf("failed finding movie with tmdb id: %q", item.TmdbId)
+	}
+
+	// route item
+	route, err := p.routes.Match(item)
+	if err != nil {
+		return errors.WithMessage(err, "failed routing item")
+	}
+
+	qualityProfileId, rootFolder := p.qualityProfileId, p.cfg.RootFolder
+	monitored := getBoolOption(p.cfg.AddOptions.Monitored, true)
+	minimumAvailability := p.minimumAvailability
+
+	if route != nil {
+		p.log.Debugf("Routing %s via %s", item.String(), route.String())
+
+		if route.QualityProfile != "" {
+			qualityProfileId = p.qualityProfileIds[strings.ToLower(route.QualityProfile)]
+		}
+		if route.RootFolder != "" {
+			rootFolder = route.RootFolder
+		}
+		if route.Monitored != nil {
+			monitored = *route.Monitored
+		}
+		if route.MinimumAvailability != "" {
+			minimumAvailability, _ = getOption("minimum_availability", route.MinimumAvailability, "",
+				radarrMinimumAvailabilities)
+		}
+	}
+
+	// resolve tags
+	tagIds, err := getTagIds(ctx, p.log, p.apiUrl, p.reqHeaders, p.timeout, p.tagIds, item, p.tags,
+		route.GetCompiledTags())
 	if err != nil {
-		return fmt.Errorf("failed converting tmdb id to int: %q", item.TmdbId)
+		return errors.WithMessage(err, "failed resolving tags")
 	}
 
 	// set request params
 	params := RadarrAddRequest{
-		Title:               item.Title,
-		TitleSlug:           item.Slug,
-		Year:                item.Year,
-		QualityProfileId:    p.qualityProfileId,
-		Images:              []string{},
-		Monitored:           true,
-		RootFolderPath:      p.cfg.RootFolder,
-		MinimumAvailability: "released",
+		Title:               lookup.Title,
+		TitleSlug:           lookup.TitleSlug,
+		Year:                lookup.Year,
+		QualityProfileId:    qualityProfileId,
+		Images:              lookup.Images,
+		Tags:                tagIds,
+		Monitored:           monitored,
+		RootFolderPath:      rootFolder,
+		MinimumAvailability: minimumAvailability,
 		AddOptions: RadarrAddOptions{
-			SearchForMovie:             true,
+			SearchForMovie:             getBoolOption(p.cfg.AddOptions.Search, true),
 			IgnoreEpisodesWithFiles:    false,
 			IgnoreEpisodesWithoutFiles: false,
 		},
-		TmdbId: tmdbId,
+		TmdbId: lookup.TmdbId,
+		ImdbId: lookup.ImdbId,
 	}
 
 	// send request
-	resp, err := web.GetResponse(web.POST, web.JoinURL(p.apiUrl, "movie"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "movie"), p.timeout, p.reqHeaders,
 		req.BodyJSON(params))
 	if err != nil {
 		return errors.New("failed retrieving add movies api response")
@@ -232,9 +433,25 @@ func (p *Radarr) AddMedia(item *config.MediaItem) error {
 	return nil
 }
 
-func (p *Radarr) GetExistingMedia() (map[string]config.MediaItem, error) {
+func (p *Radarr) ExcludeMedia(ctx context.Context, item *config.MediaItem) error {
+	tmdbId, err := strconv.Atoi(item.TmdbId)
+	if err != nil || tmdbId < 1 {
+		return fmt.Errorf("failed excluding movie with invalid tmdb id: %q", item.TmdbId)
+	}
+
+	// set request params
+	params := RadarrExclusion{
+		TmdbId:     tmdbId,
+		MovieTitle: item.Title,
+		MovieYear:  item.Year,
+	}
+
+	return addExclusion(ctx, p.apiUrl, p.reqHeaders, p.timeout, "exclusions", params)
+}
+
+func (p *Radarr) GetExistingMedia(ctx context.Context) (*config.MediaIndex, error) {
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "movie"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "movie"), p.timeout, p.reqHeaders,
 		&pvrDefaultRetry)
 	if err != nil {
 		return nil, errors.New("failed retrieving movies api response")
@@ -253,44 +470,30 @@ func (p *Radarr) GetExistingMedia() (map[string]config.MediaItem, error) {
 	}
 
 	// parse response
-	existingMediaItems := make(map[string]config.MediaItem)
-	itemsSize := 0
+	existingMediaItems := config.NewMediaIndex()
 
 	for _, item := range s {
-		added := false
-
-		if item.ImdbId != "" {
-			existingMediaItems[item.ImdbId] = config.MediaItem{
-				Provider:  "radarr",
-				ImdbId:    item.ImdbId,
-				Title:     item.Title,
-				Date:      time.Time{},
-				Genres:    nil,
-				Languages: nil,
-			}
-
-			added = true
+		mediaItem := config.MediaItem{
+			Provider: "radarr",
+			ImdbId:   item.ImdbId,
+			Title:    item.Title,
 		}
 
 		if item.TmdbId > 0 {
-			tmdbId := strconv.Itoa(item.TmdbId)
-			existingMediaItems[tmdbId] = config.MediaItem{
-				Provider:  "radarr",
-				TmdbId:    tmdbId,
-				Title:     item.Title,
-				Date:      time.Time{},
-				Genres:    nil,
-				Languages: nil,
-			}
-
-			added = true
+			mediaItem.TmdbId = strconv.Itoa(item.TmdbId)
 		}
 
-		if added {
-			itemsSize++
-		}
+		existingMediaItems.Add(mediaItem)
+	}
+
+	// add exclusions
+	if err := p.addExclusions(ctx, existingMediaItems); err != nil {
+		return nil, err
 	}
 
-	p.log.WithField("movies", itemsSize).Info("Retrieved media items")
+	p.log.WithFields(logrus.Fields{
+		"movies":   existingMediaItems.Size(),
+		"excluded": existingMediaItems.ExcludedSize(),
+	}).Info("Retrieved media items")
 	return existingMediaItems, nil
 }
@@ -0,0 +1,114 @@
+package pvr
+
+import (
+	"fmt"
+
+	"github.com/l3uddz/mediarr/config"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/pkg/errors"
+)
+
+/* Structs */
+
+type Routes struct {
+	routes []*Route
+}
+
+type Route struct {
+	*config.PvrRoute
+	Index        int
+	CompiledTags *Tags
+
+	when *vm.Program
+}
+
+/* Initializer */
+
+func NewRoutes(cfg []config.PvrRoute) (*Routes, error) {
+	r := &Routes{routes: make([]*Route, 0)}
+	exprEnv := &config.ExprEnv{}
+
+	for pos := range cfg {
+		c := &cfg[pos]
+		if c.When == "" {
+			return nil, fmt.Errorf("route %d must have a when expression", pos)
+		}
+
+		// compile condition
+		program, err := expr.Compile(c.When, expr.Env(exprEnv), expr.AsBool())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed compiling route expression for: %q", c.When)
+		}
+
+		// compile tags
+		tags, err := NewTags(c.Tags)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed compiling tags for route %d", pos)
+		}
+
+		r.routes = append(r.routes, &Route{
+			PvrRoute:     c,
+			Index:        pos,
+			CompiledTags: tags,
+			when:         program,
+		})
+	}
+
+	return r, nil
+}
+
+/* Public */
+
+func (r *Routes) Match(mediaItem *config.MediaItem) (*Route, error) {
+	exprItem := config.GetExprEnv(mediaItem)
+
+	// the first matching route wins
+	for _, route := range r.routes {
+		matches, err := runExpression("route", route.when, exprItem)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			return route, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *Routes) GetQualityProfiles() []string {
+	profiles := make([]string, 0)
+
+	for _, route := range r.routes {
+		if route.QualityProfile != "" {
+			profiles = append(profiles, route.QualityProfile)
+		}
+	}
+
+	return profiles
+}
+
+func (r *Routes) HasTags() bool {
+	for _, route := range r.routes {
+		if len(route.Tags) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Route) GetCompiledTags() *Tags {
+	if r == nil {
+		return nil
+	}
+
+	return r.CompiledTags
+}
+
+func (r *Route) String() string {
+	return fmt.Sprintf("routes[%d]: %s", r.Index, r.When)
+}
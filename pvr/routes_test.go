@@ -0,0 +1,91 @@
+package pvr
+
+import (
+	"testing"
+
+	"github.com/l3uddz/mediarr/config"
+)
+
+/* Test Route Match */
+
+func TestRouteMatch(t *testing.T) {
+	routes, err := NewRoutes([]config.PvrRoute{
+		{When: `"anime" in Genres`, RootFolder: "/mnt/Media/Anime"},
+		{When: `"animation" in Genres || "anime" in Genres`, RootFolder: "/mnt/Media/Kids"},
+		{When: `"documentary" in Genres`, QualityProfile: "HD-720p"},
+	})
+	if err != nil {
+		t.Fatalf("Failed compiling routes: %v", err)
+	}
+
+	tests := []struct {
+		item  config.MediaItem
+		index int
+	}{
+		{config.MediaItem{Title: "Anime", Genres: []string{"animation", "anime"}}, 0},
+		{config.MediaItem{Title: "Cartoon", Genres: []string{"animation"}}, 1},
+		{config.MediaItem{Title: "Documentary", Genres: []string{"documentary"}}, 2},
+		{config.MediaItem{Title: "Drama", Genres: []string{"drama"}}, -1},
+	}
+
+	for _, test := range tests {
+		route, err := routes.Match(&test.item)
+		if err != nil {
+			t.Errorf("Failed matching routes for %q: %v", test.item.Title, err)
+			continue
+		}
+
+		index := -1
+		if route != nil {
+			index = route.Index
+		}
+
+		if index != test.index {
+			t.Errorf("Expected %q to match route %d, got %d", test.item.Title, test.index, index)
+		}
+	}
+}
+
+/* Test Route Tags */
+
+func TestRouteTags(t *testing.T) {
+	routes, err := NewRoutes([]config.PvrRoute{
+		{When: `"anime" in Genres`, RootFolder: "/mnt/Media/Anime", Tags: []config.PvrTag{{Name: "anime"}}},
+		{When: `"documentary" in Genres`, QualityProfile: "HD-720p"},
+	})
+	if err != nil {
+		t.Fatalf("Failed compiling routes: %v", err)
+	}
+
+	// tags defined only on a route must still be loaded from the pvr
+	if !routes.HasTags() {
+		t.Fatalf("Expected routes to have tags")
+	}
+
+	item := config.MediaItem{Title: "Anime", Genres: []string{"anime"}}
+	route, err := routes.Match(&item)
+	if err != nil || route == nil {
+		t.Fatalf("Failed matching routes for %q: %v", item.Title, err)
+	}
+
+	names, err := getTagNames(&item, nil, route.GetCompiledTags())
+	if err != nil {
+		t.Fatalf("Failed getting tag names for %q: %v", item.Title, err)
+	}
+
+	if len(names) != 1 || names[0] != "anime" {
+		t.Errorf("Expected %q to be tagged [anime], got %v", item.Title, names)
+	}
+
+	// routes without tags
+	routes, err = NewRoutes([]config.PvrRoute{
+		{When: `"documentary" in Genres`, QualityProfile: "HD-720p"},
+	})
+	if err != nil {
+		t.Fatalf("Failed compiling routes: %v", err)
+	}
+
+	if routes.HasTags() {
+		t.Errorf("Expected routes to have no tags")
+	}
+}
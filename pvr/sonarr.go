@@ -1,22 +1,26 @@
 package pvr
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/l3uddz/mediarr/config"
 	"github.com/l3uddz/mediarr/logger"
 	"github.com/l3uddz/mediarr/utils/web"
 
-	"github.com/antonmedv/expr"
-	"github.com/antonmedv/expr/vm"
 	"github.com/imroc/req"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	sonarrMonitorOptions = []string{"all", "future", "missing", "existing", "firstSeason", "latestSeason", "pilot",
+		"none"}
+	sonarrSeriesTypes = []string{"standard", "daily", "anime"}
+)
+
 /* Structs */
 
 type Sonarr struct {
@@ -28,11 +32,20 @@ type Sonarr struct {
 	languageProfileId int
 	timeout           int
 
-	ignoresExpr []*vm.Program
-}
+	filters *Filters
+	tags    *Tags
+	tagIds  map[string]int
+	routes  *Routes
+
+	qualityProfileIds map[string]int
+
+	version      string
+	majorVersion int
 
-type SonarrSystemStatus struct {
-	Version string
+	lookups map[string]*SonarrSeriesLookup
+
+	monitor    string
+	seriesType string
 }
 
 type SonarrQualityProfiles struct {
@@ -49,6 +62,8 @@ type SonarrSeries struct {
 	Title  string
 	Status string
 	TvdbId int
+	TmdbId int
+	ImdbId string
 }
 
 type SonarrAddRequest struct {
@@ -56,35 +71,56 @@ type SonarrAddRequest struct {
 	TitleSlug         string           `json:"titleSlug"`
 	Year              int              `json:"year"`
 	QualityProfileId  int              `json:"qualityProfileId"`
-	LanguageProfileId int              `json:"languageProfileId"`
-	Images            []string         `json:"images"`
-	Tags              []string         `json:"tags"`
+	LanguageProfileId int              `json:"languageProfileId,omitempty"`
+	Images            []SonarrImage    `json:"images"`
+	Tags              []int            `json:"tags"`
 	Monitored         bool             `json:"monitored"`
 	RootFolderPath    string           `json:"rootFolderPath"`
 	AddOptions        SonarrAddOptions `json:"addOptions"`
-	Seasons           []string         `json:"seasons"`
+	Seasons           []SonarrSeason   `json:"seasons"`
 	SeriesType        string           `json:"seriesType"`
 	SeasonFolder      bool             `json:"seasonFolder"`
 	TvdbId            int              `json:"tvdbId"`
+	ImdbId            string           `json:"imdbId,omitempty"`
+}
+
+type SonarrSeriesLookup struct {
+	Title     string
+	TitleSlug string
+	Year      int
+	TvdbId    int
+	ImdbId    string
+	Images    []SonarrImage
+	Seasons   []SonarrSeason
+}
+
+type SonarrImage struct {
+	CoverType string `json:"coverType"`
+	Url       string `json:"url,omitempty"`
+	RemoteUrl string `json:"remoteUrl,omitempty"`
+}
+
+type SonarrSeason struct {
+	SeasonNumber int  `json:"seasonNumber"`
+	Monitored    bool `json:"monitored"`
+}
+
+type SonarrExclusion struct {
+	Id     int    `json:"id,omitempty"`
+	TvdbId int    `json:"tvdbId"`
+	Title  string `json:"title"`
 }
 
 type SonarrAddOptions struct {
-	SearchForMissingEpisodes   bool `json:"searchForMissingEpisodes"`
-	IgnoreEpisodesWithFiles    bool `json:"ignoreEpisodesWithFiles"`
-	IgnoreEpisodesWithoutFiles bool `json:"ignoreEpisodesWithoutFiles"`
+	SearchForMissingEpisodes   bool   `json:"searchForMissingEpisodes"`
+	IgnoreEpisodesWithFiles    bool   `json:"ignoreEpisodesWithFiles"`
+	IgnoreEpisodesWithoutFiles bool   `json:"ignoreEpisodesWithoutFiles"`
+	Monitor                    string `json:"monitor,omitempty"`
 }
 
 /* Initializer */
 
 func NewSonarr(name string, c *config.Pvr) *Sonarr {
-	// set api url
-	apiUrl := ""
-	if strings.Contains(c.URL, "/api") {
-		apiUrl = c.URL
-	} else {
-		apiUrl = web.JoinURL(c.URL, "api", "v3")
-	}
-
 	// set headers
 	reqHeaders := req.Header{
 		"X-Api-Key": c.ApiKey,
@@ -93,25 +129,137 @@ func NewSonarr(name string, c *config.Pvr) *Sonarr {
 	return &Sonarr{
 		cfg:        c,
 		log:        logger.GetLogger(name),
-		apiUrl:     apiUrl,
+		apiUrl:     getApiUrl(c.URL, "v3"),
 		reqHeaders: reqHeaders,
 		timeout:    pvrDefaultTimeout,
+		lookups:    make(map[string]*SonarrSeriesLookup),
+		tagIds:     make(map[string]int),
 	}
 }
 
 /* Private */
 
 func (p *Sonarr) compileExpressions() error {
-	exprEnv := &config.ExprEnv{}
+	filters, err := NewFilters(&p.cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	p.filters = filters
+
+	tags, err := NewTags(p.cfg.Tags)
+	if err != nil {
+		return err
+	}
+
+	p.tags = tags
+
+	routes, err := NewRoutes(p.cfg.Routes)
+	if err != nil {
+		return err
+	}
+
+	p.routes = routes
+	return nil
+}
+
+func (p *Sonarr) validateAddOptions() error {
+	var err error
+
+	// validate monitor option
+	if p.monitor, err = getOption("monitor", p.cfg.AddOptions.Monitor, "", sonarrMonitorOptions); err != nil {
+		return err
+	}
+
+	// validate series type
+	if p.seriesType, err = getOption("series_type", p.cfg.AddOptions.SeriesType, "standard",
+		sonarrSeriesTypes); err != nil {
+		return err
+	}
+
+	// warn about radarr only options
+	if p.cfg.AddOptions.MinimumAvailability != "" {
+		p.log.Warn("Ignoring minimum_availability, it is only supported by radarr")
+	}
+
+	for pos, route := range p.cfg.Routes {
+		if route.MinimumAvailability != "" {
+			p.log.Warnf("Ignoring minimum_availability of route %d, it is only supported by radarr", pos)
+		}
+	}
+
+	return nil
+}
+
+func (p *Sonarr) lookupSeries(ctx context.Context, tvdbId string) (*SonarrSeriesLookup, error) {
+	// have we already looked up this item?
+	if lookup, ok := p.lookups[tvdbId]; ok {
+		return lookup, nil
+	}
+
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "series", "lookup"), p.timeout, p.reqHeaders,
+		req.Param{"term": "tvdb:" + tvdbId}, &pvrDefaultRetry)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed retrieving series lookup api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return nil, fmt.Errorf("failed retrieving valid series lookup api response: %s", resp.Response().Status)
+	}
+
+	// decode response
+	var s []SonarrSeriesLookup
+	if err := resp.ToJSON(&s); err != nil {
+		return nil, errors.WithMessage(err, "failed decoding series lookup api response")
+	}
+
+	// find series (nil when it does not exist upstream)
+	var lookup *SonarrSeriesLookup
+	for pos, series := range s {
+		if strconv.Itoa(series.TvdbId) == tvdbId {
+			lookup = &s[pos]
+			break
+		}
+	}
+
+	p.lookups[tvdbId] = lookup
+	return lookup, nil
+}
+
+func (p *Sonarr) addExclusions(ctx context.Context, existingMediaItems *config.MediaIndex) error {
+	// send request
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "importlistexclusion"), p.timeout, p.reqHeaders,
+		&pvrDefaultRetry)
+	if err != nil {
+		return errors.WithMessage(err, "failed retrieving exclusions api response")
+	}
+	defer web.DrainAndClose(resp.Response().Body)
+
+	// validate response
+	if resp.Response().StatusCode != 200 {
+		return fmt.Errorf("failed retrieving valid exclusions api response: %s", resp.Response().Status)
+	}
 
-	// compile ignores
-	for _, ignoreExpr := range p.cfg.Filters.Ignores {
-		program, err := expr.Compile(ignoreExpr, expr.Env(exprEnv), expr.AsBool())
-		if err != nil {
-			return errors.Wrapf(err, "failed compiling ignore expression for: %q", ignoreExpr)
+	// decode response
+	var s []SonarrExclusion
+	if err := resp.ToJSON(&s); err != nil {
+		return errors.WithMessage(err, "failed decoding exclusions api response")
+	}
+
+	// add excluded items
+	for _, item := range s {
+		if item.TvdbId < 1 {
+			continue
 		}
 
-		p.ignoresExpr = append(p.ignoresExpr, program)
+		existingMediaItems.AddExcluded(config.MediaItem{
+			Provider: "sonarr",
+			TvdbId:   strconv.Itoa(item.TvdbId),
+			Title:    item.Title,
+		})
 	}
 
 	return nil
@@ -119,7 +267,7 @@ func (p *Sonarr) compileExpressions() error {
 
 /* Interface Implements */
 
-func (p *Sonarr) Init(mediaType MediaType) error {
+func (p *Sonarr) Init(ctx context.Context, mediaType MediaType) error {
 	// validate we support this media type
 	switch mediaType {
 	case SHOW:
@@ -133,20 +281,47 @@ func (p *Sonarr) Init(mediaType MediaType) error {
 		return err
 	}
 
-	// find quality profile
-	if id, err := p.GetQualityProfileId(p.cfg.QualityProfile); err != nil {
+	// validate add options
+	if err := p.validateAddOptions(); err != nil {
 		return err
-	} else {
-		p.qualityProfileId = id
+	}
 
-		p.log.WithFields(logrus.Fields{
-			"quality_name": p.cfg.QualityProfile,
-			"quality_id":   p.qualityProfileId,
-		}).Info("Found quality profile")
+	// detect version
+	status, err := getSystemStatus(ctx, p.apiUrl, p.reqHeaders, p.timeout)
+	if err != nil {
+		return err
 	}
 
-	// find language profile
-	if id, err := p.GetLanguageProfileId(p.cfg.LanguageProfile); err != nil {
+	p.version = status.Version
+	if p.majorVersion, err = getMajorVersion(status.Version); err != nil {
+		return err
+	} else if p.majorVersion < 3 {
+		return fmt.Errorf("unsupported sonarr version: %s", p.version)
+	}
+
+	p.log.WithField("version", p.version).Info("Detected version")
+
+	// load tags
+	if len(p.cfg.Tags) > 0 || p.routes.HasTags() {
+		if p.tagIds, err = getTags(ctx, p.apiUrl, p.reqHeaders, p.timeout); err != nil {
+			return err
+		}
+	}
+
+	// find quality profiles
+	if p.qualityProfileIds, err = getQualityProfileIds(ctx, p.log, p.cfg.QualityProfile, p.routes,
+		p.GetQualityProfileId); err != nil {
+		return err
+	}
+
+	p.qualityProfileId = p.qualityProfileIds[strings.ToLower(p.cfg.QualityProfile)]
+
+	// find language profile (removed in v4)
+	if p.majorVersion >= 4 {
+		if p.cfg.LanguageProfile != "" {
+			p.log.Warn("Ignoring language_profile, it is not supported by sonarr v4")
+		}
+	} else if id, err := p.GetLanguageProfileId(ctx, p.cfg.LanguageProfile); err != nil {
 		return err
 	} else {
 		p.languageProfileId = id
@@ -160,31 +335,39 @@ func (p *Sonarr) Init(mediaType MediaType) error {
 	return nil
 }
 
-func (p *Sonarr) ShouldIgnore(mediaItem *config.MediaItem) (bool, error) {
-	exprItem := config.GetExprEnv(mediaItem)
+func (p *Sonarr) LookupMedia(ctx context.Context, item *config.MediaItem) (bool, error) {
+	if item.TvdbId == "" {
+		return false, nil
+	}
 
-	for _, expression := range p.ignoresExpr {
-		result, err := expr.Run(expression, exprItem)
-		if err != nil {
-			return true, errors.Wrap(err, "failed checking ignore expression")
-		}
+	lookup, err := p.lookupSeries(ctx, item.TvdbId)
+	if err != nil {
+		return false, err
+	}
 
-		expResult, ok := result.(bool)
-		if !ok {
-			return true, errors.New("failed type asserting ignore expression result")
-		}
+	return lookup != nil, nil
+}
 
-		if expResult {
-			return true, nil
-		}
+func (p *Sonarr) GetVersion() string {
+	return p.version
+}
+
+func (p *Sonarr) ShouldIgnore(mediaItem *config.MediaItem) (bool, error) {
+	match, err := p.ExplainIgnore(mediaItem)
+	if err != nil {
+		return true, err
 	}
 
-	return false, nil
+	return match != nil, nil
 }
 
-func (p *Sonarr) GetQualityProfileId(profileName string) (int, error) {
+func (p *Sonarr) ExplainIgnore(mediaItem *config.MediaItem) (*FilterMatch, error) {
+	return p.filters.ExplainIgnore(mediaItem)
+}
+
+func (p *Sonarr) GetQualityProfileId(ctx context.Context, profileName string) (int, error) {
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "qualityprofile"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "qualityprofile"), p.timeout, p.reqHeaders,
 		&pvrDefaultRetry)
 	if err != nil {
 		return 0, errors.New("failed retrieving quality profiles api response")
@@ -212,9 +395,9 @@ func (p *Sonarr) GetQualityProfileId(profileName string) (int, error) {
 	return 0, fmt.Errorf("failed finding quality profile: %q", profileName)
 }
 
-func (p *Sonarr) GetLanguageProfileId(profileName string) (int, error) {
+func (p *Sonarr) GetLanguageProfileId(ctx context.Context, profileName string) (int, error) {
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "languageprofile"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "languageprofile"), p.timeout, p.reqHeaders,
 		&pvrDefaultRetry)
 	if err != nil {
 		return 0, errors.New("failed retrieving language profiles api response")
@@ -242,37 +425,80 @@ func (p *Sonarr) GetLanguageProfileId(profileName string) (int, error) {
 	return 0, fmt.Errorf("failed finding language profile: %q", profileName)
 }
 
-func (p *Sonarr) AddMedia(item *config.MediaItem) error {
-	// convert TvdbId to int
-	tvdbId, err := strconv.Atoi(item.TvdbId)
+func (p *Sonarr) AddMedia(ctx context.Context, item *config.MediaItem) error {
+	// lookup series
+	lookup, err := p.lookupSeries(ctx, item.TvdbId)
+	if err != nil {
+		return errors.WithMessage(err, "failed looking up series")
+	} else if lookup == nil {
+		return fmt.Errorf("failed finding series with tvdb id: %q", item.TvdbId)
+	}
+
+	// route item
+	route, err := p.routes.Match(item)
+	if err != nil {
+		return errors.WithMessage(err, "failed routing item")
+	}
+
+	qualityProfileId, rootFolder := p.qualityProfileId, p.cfg.RootFolder
+	monitored := getBoolOption(p.cfg.AddOptions.Monitored, true)
+
+	if route != nil {
+		p.log.Debugf("Routing %s via %s", item.String(), route.String())
+
+		if route.QualityProfile != "" {
+			qualityProfileId = p.qualityProfileIds[strings.ToLower(route.QualityProfile)]
+		}
+		if route.RootFolder != "" {
+			rootFolder = route.RootFolder
+		}
+		if route.Monitored != nil {
+			monitored = *route.Monitored
+		}
+	}
+
+	// resolve tags
+	tagIds, err := getTagIds(ctx, p.log, p.apiUrl, p.reqHeaders, p.timeout, p.tagIds, item, p.tags,
+		route.GetCompiledTags())
 	if err != nil {
-		return fmt.Errorf("failed converting tvdb id to int: %q", item.TvdbId)
+		return errors.WithMessage(err, "failed resolving tags")
+	}
+
+	// monitor every season except specials
+	seasons := make([]SonarrSeason, 0, len(lookup.Seasons))
+	for _, season := range lookup.Seasons {
+		seasons = append(seasons, SonarrSeason{
+			SeasonNumber: season.SeasonNumber,
+			Monitored:    monitored && season.SeasonNumber > 0,
+		})
 	}
 
 	// set request params
 	params := SonarrAddRequest{
-		Title:             item.Title,
-		TitleSlug:         item.Slug,
-		Year:              item.Year,
-		QualityProfileId:  p.qualityProfileId,
+		Title:             lookup.Title,
+		TitleSlug:         lookup.TitleSlug,
+		Year:              lookup.Year,
+		QualityProfileId:  qualityProfileId,
 		LanguageProfileId: p.languageProfileId,
-		Images:            []string{},
-		Tags:              []string{},
-		Monitored:         true,
-		RootFolderPath:    p.cfg.RootFolder,
+		Images:            lookup.Images,
+		Tags:              tagIds,
+		Monitored:         monitored,
+		RootFolderPath:    rootFolder,
 		AddOptions: SonarrAddOptions{
-			SearchForMissingEpisodes:   true,
+			SearchForMissingEpisodes:   getBoolOption(p.cfg.AddOptions.Search, true),
 			IgnoreEpisodesWithFiles:    false,
 			IgnoreEpisodesWithoutFiles: false,
+			Monitor:                    p.monitor,
 		},
-		Seasons:      []string{},
-		SeriesType:   "standard",
-		SeasonFolder: true,
-		TvdbId:       tvdbId,
+		Seasons:      seasons,
+		SeriesType:   p.seriesType,
+		SeasonFolder: getBoolOption(p.cfg.AddOptions.SeasonFolder, true),
+		TvdbId:       lookup.TvdbId,
+		ImdbId:       lookup.ImdbId,
 	}
 
 	// send request
-	resp, err := web.GetResponse(web.POST, web.JoinURL(p.apiUrl, "series"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.POST, web.JoinURL(p.apiUrl, "series"), p.timeout, p.reqHeaders,
 		req.BodyJSON(params))
 	if err != nil {
 		return errors.New("failed retrieving add series api response")
@@ -287,9 +513,24 @@ func (p *Sonarr) AddMedia(item *config.MediaItem) error {
 	return nil
 }
 
-func (p *Sonarr) GetExistingMedia() (map[string]config.MediaItem, error) {
+func (p *Sonarr) ExcludeMedia(ctx context.Context, item *config.MediaItem) error {
+	tvdbId, err := strconv.Atoi(item.TvdbId)
+	if err != nil || tvdbId < 1 {
+		return fmt.Errorf("failed excluding series with invalid tvdb id: %q", item.TvdbId)
+	}
+
+	// set request params
+	params := SonarrExclusion{
+		TvdbId: tvdbId,
+		Title:  item.Title,
+	}
+
+	return addExclusion(ctx, p.apiUrl, p.reqHeaders, p.timeout, "importlistexclusion", params)
+}
+
+func (p *Sonarr) GetExistingMedia(ctx context.Context) (*config.MediaIndex, error) {
 	// send request
-	resp, err := web.GetResponse(web.GET, web.JoinURL(p.apiUrl, "series"), p.timeout, p.reqHeaders,
+	resp, err := web.GetResponse(ctx, web.GET, web.JoinURL(p.apiUrl, "series"), p.timeout, p.reqHeaders,
 		&pvrDefaultRetry)
 	if err != nil {
 		return nil, errors.New("failed retrieving series api response")
@@ -308,23 +549,33 @@ func (p *Sonarr) GetExistingMedia() (map[string]config.MediaItem, error) {
 	}
 
 	// parse response
-	existingMediaItems := make(map[string]config.MediaItem)
-	itemsSize := 0
+	existingMediaItems := config.NewMediaIndex()
 
 	for _, item := range s {
-		itemsSize++
-
-		itemId := strconv.Itoa(item.TvdbId)
-		existingMediaItems[itemId] = config.MediaItem{
-			Provider:  "sonarr",
-			TvdbId:    itemId,
-			Title:     item.Title,
-			Date:      time.Time{},
-			Genres:    nil,
-			Languages: nil,
+		mediaItem := config.MediaItem{
+			Provider: "sonarr",
+			ImdbId:   item.ImdbId,
+			Title:    item.Title,
+		}
+
+		if item.TvdbId > 0 {
+			mediaItem.TvdbId = strconv.Itoa(item.TvdbId)
 		}
+		if item.TmdbId > 0 {
+			mediaItem.TmdbId = strconv.Itoa(item.TmdbId)
+		}
+
+		existingMediaItems.Add(mediaItem)
+	}
+
+	// add exclusions
+	if err := p.addExclusions(ctx, existingMediaItems); err != nil {
+		return nil, err
 	}
 
-	p.log.WithField("shows", itemsSize).Info("Retrieved media items")
+	p.log.WithFields(logrus.Fields{
+		"shows":    existingMediaItems.Size(),
+		"excluded": existingMediaItems.ExcludedSize(),
+	}).Info("Retrieved media items")
 	return existingMediaItems, nil
 }
@@ -0,0 +1,168 @@
+package pvr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/l3uddz/mediarr/config"
+	"github.com/l3uddz/mediarr/utils/lists"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/pkg/errors"
+)
+
+var (
+	tagPlaceholderRegex = regexp.MustCompile(`{{(.+?)}}`)
+	tagInvalidCharRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+	tagDashesRegex      = regexp.MustCompile(`-{2,}`)
+)
+
+/* Structs */
+
+type Tags struct {
+	tags []*tag
+}
+
+type tag struct {
+	name         string
+	placeholders []*vm.Program
+	when         *vm.Program
+}
+
+/* Initializer */
+
+func NewTags(cfg []config.PvrTag) (*Tags, error) {
+	t := &Tags{tags: make([]*tag, 0)}
+	exprEnv := &config.ExprEnv{}
+
+	for _, c := range cfg {
+		if strings.TrimSpace(c.Name) == "" {
+			return nil, errors.New("tag name must not be empty")
+		}
+
+		nt := &tag{name: c.Name}
+
+		// compile placeholders
+		for _, m := range tagPlaceholderRegex.FindAllStringSubmatch(c.Name, -1) {
+			program, err := expr.Compile(strings.TrimSpace(m[1]), expr.Env(exprEnv))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed compiling tag placeholder for: %q", c.Name)
+			}
+
+			nt.placeholders = append(nt.placeholders, program)
+		}
+
+		// compile condition
+		if c.When != "" {
+			program, err := expr.Compile(c.When, expr.Env(exprEnv), expr.AsBool())
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed compiling tag expression for: %q", c.Name)
+			}
+
+			nt.when = program
+		}
+
+		t.tags = append(t.tags, nt)
+	}
+
+	return t, nil
+}
+
+/* Public */
+
+func (t *Tags) GetNames(mediaItem *config.MediaItem) ([]string, error) {
+	exprItem := config.GetExprEnv(mediaItem)
+	names := make([]string, 0)
+
+	for _, nt := range t.tags {
+		// skip tags whose condition does not match
+		if nt.when != nil {
+			matches, err := runExpression("tag", nt.when, exprItem)
+			if err != nil {
+				return nil, err
+			}
+
+			if !matches {
+				continue
+			}
+		}
+
+		// render placeholders
+		name := nt.name
+		if len(nt.placeholders) > 0 {
+			var err error
+			pos := 0
+
+			name = tagPlaceholderRegex.ReplaceAllStringFunc(nt.name, func(string) string {
+				program := nt.placeholders[pos]
+				pos++
+
+				result, e := expr.Run(program, exprItem)
+				if e != nil {
+					err = errors.Wrapf(e, "failed rendering tag: %q", nt.name)
+					return ""
+				}
+
+				return formatTagValue(result)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// skip empty and duplicate tags
+		name = sanitizeTagName(name)
+		if name == "" || lists.StringListContains(names, name, true) {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+/* Private */
+
+func getTagNames(mediaItem *config.MediaItem, tags ...*Tags) ([]string, error) {
+	names := make([]string, 0)
+
+	for _, t := range tags {
+		if t == nil {
+			continue
+		}
+
+		tagNames, err := t.GetNames(mediaItem)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range tagNames {
+			if !lists.StringListContains(names, name, true) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func formatTagValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, "-")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sanitizeTagName(name string) string {
+	// sonarr and radarr only allow lowercase letters, numbers and dashes
+	name = tagInvalidCharRegex.ReplaceAllString(strings.ToLower(name), "-")
+	name = tagDashesRegex.ReplaceAllString(name, "-")
+	return strings.Trim(name, "-")
+}
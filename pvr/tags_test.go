@@ -0,0 +1,44 @@
+package pvr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/l3uddz/mediarr/config"
+)
+
+/* Test Tag Names */
+
+func TestTagNames(t *testing.T) {
+	tags, err := NewTags([]config.PvrTag{
+		{Name: "mediarr"},
+		{Name: "src-{{Provider}}"},
+		{Name: "genre-horror", When: `"horror" in Genres`},
+		{Name: "{{ Network }}"},
+		{Name: "Mediarr"},
+	})
+	if err != nil {
+		t.Fatalf("Failed compiling tags: %v", err)
+	}
+
+	tests := []struct {
+		item config.MediaItem
+		want string
+	}{
+		{config.MediaItem{Provider: "trakt", Genres: []string{"horror"}}, "mediarr,src-trakt,genre-horror"},
+		{config.MediaItem{Provider: "tmdb", Genres: []string{"drama"}, Network: "Apple TV+"},
+			"mediarr,src-tmdb,apple-tv"},
+	}
+
+	for _, test := range tests {
+		names, err := tags.GetNames(&test.item)
+		if err != nil {
+			t.Errorf("Failed resolving tag names for %+v: %v", test.item, err)
+			continue
+		}
+
+		if got := strings.Join(names, ","); got != test.want {
+			t.Errorf("Expected tags %q, got %q", test.want, got)
+		}
+	}
+}
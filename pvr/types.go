@@ -1,8 +1,30 @@
 package pvr
 
+import "fmt"
+
 type MediaType int
 
 const (
 	SHOW MediaType = iota + 1
 	MOVIE
+	MUSIC
 )
+
+type FilterType string
+
+const (
+	FilterTypeAccept FilterType = "accepts"
+	FilterTypeIgnore FilterType = "ignores"
+)
+
+// FilterMatch describes the filter expression that rejected an item
+type FilterMatch struct {
+	Type       FilterType
+	Index      int
+	Expression string
+	Exclude    bool
+}
+
+func (f *FilterMatch) String() string {
+	return fmt.Sprintf("%s[%d]: %s", f.Type, f.Index, f.Expression)
+}
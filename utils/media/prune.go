@@ -2,13 +2,15 @@ package media
 
 import "github.com/l3uddz/mediarr/config"
 
-func PruneExistingMedia(pvrMediaItems map[string]config.MediaItem, providerMediaItems map[string]config.MediaItem) (map[string]config.MediaItem, error) {
+func PruneExistingMedia(pvrMediaItems *config.MediaIndex, providerMediaItems map[string]config.MediaItem) (map[string]config.MediaItem, error) {
 	newMediaItems := make(map[string]config.MediaItem)
 
 	// iterate new media items
 	for mediaId, mediaItem := range providerMediaItems {
+		item := mediaItem
+
 		// do we already have this media item?
-		if _, ok := pvrMediaItems[mediaId]; ok {
+		if pvrMediaItems.Contains(&item) {
 			continue
 		}
 
@@ -20,14 +20,18 @@ func SortedMediaItemSlice(mediaItems map[string]config.MediaItem, sortType SortT
 		sortedMediaItems = append(sortedMediaItems, v)
 	}
 
+	return SortMediaItems(sortedMediaItems, sortType)
+}
+
+func SortMediaItems(mediaItems []config.MediaItem, sortType SortType) []config.MediaItem {
 	// sort items
 	switch sortType {
 	default:
 		// sort by Release Date
-		sort.Slice(sortedMediaItems, func(i, j int) bool {
-			return sortedMediaItems[i].Date.After(sortedMediaItems[j].Date)
+		sort.Slice(mediaItems, func(i, j int) bool {
+			return mediaItems[i].Date.After(mediaItems[j].Date)
 		})
 	}
 
-	return sortedMediaItems
+	return mediaItems
 }
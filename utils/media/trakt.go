@@ -1,6 +1,7 @@
 package media
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -47,15 +48,20 @@ const (
 	Tvdb TraktSearchType = "tvdb"
 )
 
-func LookupTraktId(mediaType string, providerType TraktSearchType, searchId string) (int, error) {
+func LookupTraktId(ctx context.Context, mediaType string, providerType TraktSearchType, searchId string) (int, error) {
 	// set request details
 	reqLimit := web.GetRateLimiter("trakt", 3)
 	reqHeader := req.Header{
 		"trakt-api-key": TraktClientId,
 	}
 	reqRetry := web.Retry{
-		MaxAttempts:          5,
-		RetryableStatusCodes: []int{},
+		MaxAttempts: 5,
+		RetryableStatusCodes: []int{
+			429,
+			502,
+			503,
+			504,
+		},
 		Backoff: backoff.Backoff{
 			Jitter: true,
 			Min:    1 * time.Second,
@@ -66,7 +72,7 @@ func LookupTraktId(mediaType string, providerType TraktSearchType, searchId stri
 	searchUrl := fmt.Sprintf("https://api.trakt.tv/search/%s/%s?type=%s", providerType, searchId, mediaType)
 
 	// send request
-	resp, err := web.GetResponse(web.GET, searchUrl, 30, reqHeader, &reqRetry, reqLimit)
+	resp, err := web.GetResponse(ctx, web.GET, searchUrl, 30, reqHeader, &reqRetry, reqLimit)
 	if err != nil {
 		return 0, errors.WithMessagef(err, "failed retrieving trakt %s search response for: %q", mediaType, searchId)
 	}
@@ -1,8 +1,8 @@
 package web
 
 import (
+	"context"
 	"io/ioutil"
-	"os"
 	"strings"
 	"time"
 
@@ -55,9 +55,12 @@ func init() {
 
 /* Public */
 
-func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interface{}) (*req.Resp, error) {
+func GetResponse(ctx context.Context, method HTTPMethod, requestUrl string, timeout int, v ...interface{}) (*req.Resp, error) {
 	inputs := make([]interface{}, 0)
 
+	// cancel request with context
+	inputs = append(inputs, ctx)
+
 	// prepare client
 	client := httpClient
 	if timeout > 0 {
@@ -90,6 +93,11 @@ func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interfa
 
 	// Exponential backoff
 	for {
+		// stop when context is done
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// do request
 		switch method {
 		case GET:
@@ -112,14 +120,22 @@ func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interfa
 		// validate response
 		if err != nil {
 			log.WithError(err).Debugf("Failed requesting: %q", requestUrl)
-			if os.IsTimeout(err) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			if isRetryableError(err) {
 				if retry.MaxAttempts == 0 || retry.Attempt() >= retry.MaxAttempts {
+					log.WithError(err).Warnf("Giving up on failed request after %.0f attempts: %q",
+						retry.Attempt()+1, requestUrl)
 					return nil, err
 				}
 
 				d := retry.Duration()
-				log.Debugf("Retrying failed request in %s: %q", d, requestUrl)
-				time.Sleep(d)
+				log.WithError(err).Warnf("Retrying failed request in %s: %q", d, requestUrl)
+				if err := sleep(ctx, d); err != nil {
+					return nil, err
+				}
 				continue
 			}
 
@@ -130,6 +146,10 @@ func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interfa
 		log.Tracef("Request Response: %s", resp.Response().Status)
 
 		if retry.MaxAttempts == 0 || retry.Attempt() >= retry.MaxAttempts {
+			if retry.MaxAttempts > 0 && lists.IntListContains(resp.Response().StatusCode, retry.RetryableStatusCodes) {
+				log.Warnf("Giving up on failed request after %.0f attempts: %d - %q", retry.Attempt()+1,
+					resp.Response().StatusCode, requestUrl)
+			}
 			break
 		}
 
@@ -138,11 +158,17 @@ func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interfa
 			// close response body
 			DrainAndClose(resp.Response().Body)
 
-			// retry
+			// retry (honoring the delay requested by the server)
 			d := retry.Duration()
-			log.Debugf("Retrying failed request in %s: %d - %q", d, resp.Response().StatusCode, requestUrl)
+			if retryAfter := getRetryAfter(resp.Response(), time.Now()); retryAfter > d {
+				d = retryAfter
+			}
+
+			log.Warnf("Retrying failed request in %s: %d - %q", d, resp.Response().StatusCode, requestUrl)
 
-			time.Sleep(d)
+			if err := sleep(ctx, d); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
@@ -159,7 +185,9 @@ func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interfa
 				d := retry.Duration()
 				log.Debugf("Retrying failed request in %s: %d %s - %q", d, resp.Response().StatusCode, contentType, requestUrl)
 
-				time.Sleep(d)
+				if err := sleep(ctx, d); err != nil {
+					return nil, err
+				}
 				continue
 			}
 		}
@@ -170,9 +198,9 @@ func GetResponse(method HTTPMethod, requestUrl string, timeout int, v ...interfa
 	return resp, err
 }
 
-func GetBodyBytes(method HTTPMethod, requestUrl string, timeout int, v ...interface{}) ([]byte, error) {
+func GetBodyBytes(ctx context.Context, method HTTPMethod, requestUrl string, timeout int, v ...interface{}) ([]byte, error) {
 	// send request
-	resp, err := GetResponse(method, requestUrl, timeout, v...)
+	resp, err := GetResponse(ctx, method, requestUrl, timeout, v...)
 	if err != nil {
 		return nil, err
 	}
@@ -188,8 +216,8 @@ func GetBodyBytes(method HTTPMethod, requestUrl string, timeout int, v ...interf
 	return body, nil
 }
 
-func GetBodyString(method HTTPMethod, requestUrl string, timeout int, v ...interface{}) (string, error) {
-	bodyBytes, err := GetBodyBytes(method, requestUrl, timeout, v...)
+func GetBodyString(ctx context.Context, method HTTPMethod, requestUrl string, timeout int, v ...interface{}) (string, error) {
+	bodyBytes, err := GetBodyBytes(ctx, method, requestUrl, timeout, v...)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"os"
 	"testing"
 )
@@ -9,7 +10,7 @@ import (
 
 func TestGetResponseTimeout(t *testing.T) {
 	// send request
-	resp, err := GetResponse(GET, "https://httpbin.davecheney.com/delay/5", 3)
+	resp, err := GetResponse(context.Background(), GET, "https://httpbin.davecheney.com/delay/5", 3)
 	if err != nil && !os.IsTimeout(err) {
 		t.Errorf("Expected timeout in 3 seconds but got error: %v", err)
 		return
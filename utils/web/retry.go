@@ -0,0 +1,76 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxRetryAfter caps the delay requested by a server before retrying
+	maxRetryAfter = 5 * time.Minute
+)
+
+/* Private */
+
+func isRetryableError(err error) bool {
+	switch {
+	case os.IsTimeout(err):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	default:
+		return false
+	}
+}
+
+func getRetryAfter(resp *http.Response, now time.Time) time.Duration {
+	var d time.Duration
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		// delay in seconds or a http date
+		if seconds, err := strconv.Atoi(v); err == nil {
+			d = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(v); err == nil {
+			d = date.Sub(now)
+		}
+	} else if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		// delay until the rate limit resets, as seconds or a unix timestamp
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if reset > 1000000000 {
+				d = time.Unix(reset, 0).Sub(now)
+			} else {
+				d = time.Duration(reset) * time.Second
+			}
+		}
+	}
+
+	switch {
+	case d < 0:
+		return 0
+	case d > maxRetryAfter:
+		return maxRetryAfter
+	default:
+		return d
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
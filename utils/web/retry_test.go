@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+/* Test Get Retry After */
+
+func TestGetRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected time.Duration
+	}{
+		{"none", map[string]string{}, 0},
+		{"seconds", map[string]string{"Retry-After": "10"}, 10 * time.Second},
+		{"date", map[string]string{"Retry-After": now.Add(30 * time.Second).Format(http.TimeFormat)}, 30 * time.Second},
+		{"capped", map[string]string{"Retry-After": "3600"}, maxRetryAfter},
+		{"ratelimit seconds", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "5"}, 5 * time.Second},
+		{"ratelimit timestamp", map[string]string{"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset": strconv.FormatInt(now.Add(20*time.Second).Unix(), 10)}, 20 * time.Second},
+		{"ratelimit remaining", map[string]string{"X-RateLimit-Remaining": "10", "X-RateLimit-Reset": "5"}, 0},
+	}
+
+	for _, test := range tests {
+		resp := &http.Response{Header: make(http.Header)}
+		for k, v := range test.headers {
+			resp.Header.Set(k, v)
+		}
+
+		if d := getRetryAfter(resp, now); d != test.expected {
+			t.Errorf("Expected %s retry after for %q but got %s", test.expected, test.name, d)
+		}
+	}
+}